@@ -0,0 +1,139 @@
+package lept
+
+import (
+	"cmp"
+	"slices"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// StringifyCanonical writes v in the RFC 8785 JSON Canonicalization Scheme:
+// no whitespace, object members sorted by the UTF-16 code units of their
+// keys, and numbers formatted like ECMAScript from their float64 value. The
+// output is byte-identical for equal inputs, as needed for signing. Duplicate
+// keys and strings that are not valid UTF-8 are errors.
+func StringifyCanonical(v *Value) (string, error) {
+	buf, err := appendCanonical(nil, v)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// Canonicalize parses src and returns its canonical form.
+func Canonicalize(src string) (string, error) {
+	v, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return StringifyCanonical(v)
+}
+
+func appendCanonical(buf []byte, v *Value) ([]byte, error) {
+	if v == nil {
+		return append(buf, "null"...), nil
+	}
+
+	switch v.Type {
+	case TypeNull:
+		return append(buf, "null"...), nil
+	case TypeTrue:
+		return append(buf, "true"...), nil
+	case TypeFalse:
+		return append(buf, "false"...), nil
+	case TypeNumber:
+		n, err := v.number()
+		if err != nil {
+			return nil, err
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, errorf("%w: number %s", err, n)
+		}
+		if f == 0 {
+			// -0 is written as 0
+			f = 0
+		}
+		return appendFloat(buf, f, 64)
+	case TypeString:
+		s, ok := v.U.(string)
+		if !ok {
+			return nil, ErrMismatchType
+		}
+		if !utf8.ValidString(s) {
+			return nil, errorf("%w: invalid UTF-8 in string %q", ErrUnsupportedValue, s)
+		}
+		return appendString(buf, s), nil
+	case TypeArray:
+		arr, ok := v.U.(Array)
+		if !ok {
+			return nil, ErrMismatchType
+		}
+		buf = append(buf, '[')
+		for i, e := range arr {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			var err error
+			if buf, err = appendCanonical(buf, e); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, ']'), nil
+	case TypeObject:
+		obj, ok := v.U.(Object)
+		if !ok {
+			return nil, ErrMismatchType
+		}
+		obj = slices.Clone(obj)
+		slices.SortFunc(obj, func(a, b Member) int {
+			return compareUTF16(a.K, b.K)
+		})
+		buf = append(buf, '{')
+		for i, m := range obj {
+			if i > 0 {
+				if m.K == obj[i-1].K {
+					return nil, errorf("%w: %q", ErrDuplicateKey, m.K)
+				}
+				buf = append(buf, ',')
+			}
+			if !utf8.ValidString(m.K) {
+				return nil, errorf("%w: invalid UTF-8 in key %q", ErrUnsupportedValue, m.K)
+			}
+			buf = appendString(buf, m.K)
+			buf = append(buf, ':')
+			var err error
+			if buf, err = appendCanonical(buf, m.V); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, '}'), nil
+	default:
+		return nil, errUnsupportedType(v)
+	}
+}
+
+// compareUTF16 orders strings by their UTF-16 code units, which differs from
+// code point order when a supplementary character meets one in U+E000 to
+// U+FFFF.
+func compareUTF16(a, b string) int {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			if c := cmp.Compare(firstUnit(ra), firstUnit(rb)); c != 0 {
+				return c
+			}
+			return cmp.Compare(ra, rb)
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func firstUnit(r rune) rune {
+	if r >= 0x10000 {
+		r, _ = utf16.EncodeRune(r)
+	}
+	return r
+}
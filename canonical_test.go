@@ -0,0 +1,117 @@
+package lept_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func TestCanonicalize(t *testing.T) {
+	// RFC 8785, section 3.2.2 and 3.2.3
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`{
+			"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			"literals": [null, true, false]
+		}`, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`},
+		{`{
+			"€": "Euro Sign",
+			"\r": "Carriage Return",
+			"דּ": "Hebrew Letter Dalet With Dagesh",
+			"1": "One",
+			"😀": "Emoji: Grinning Face",
+			"\u0080": "Control",
+			"ö": "Latin Small Letter O With Diaeresis"
+		}`, `{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control","ö":"Latin Small Letter O With Diaeresis","€":"Euro Sign","😀":"Emoji: Grinning Face","` + "\ufb33" + `":"Hebrew Letter Dalet With Dagesh"}`},
+		{` [ ] `, `[]`},
+		{`{"b": {"z": 1, "y": [{"d": 0, "c": -0}]}, "a": "\u001f"}`, `{"a":"\u001f","b":{"y":[{"c":0,"d":0}],"z":1}}`},
+		{`12345678901234567890`, `12345678901234567000`},
+	}
+	for _, tt := range tests {
+		got, err := lept.Canonicalize(tt.src)
+		if err != nil {
+			t.Errorf("canonicalize %s failed: %v", tt.src, err)
+			continue
+		}
+		assertValue(t, got, tt.want)
+
+		again, _ := lept.Canonicalize(got)
+		assertValue(t, again, got)
+	}
+}
+
+func TestCanonicalNumber(t *testing.T) {
+	// RFC 8785, Appendix B
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+	for _, tt := range tests {
+		got, err := lept.StringifyCanonical(lept.NewNumber(math.Float64frombits(tt.bits)))
+		if err != nil {
+			t.Errorf("%016x failed: %v", tt.bits, err)
+			continue
+		}
+		assertValue(t, got, tt.want)
+
+		// the literal of a parsed number is reformatted the same way
+		got, _ = lept.Canonicalize(tt.want)
+		assertValue(t, got, tt.want)
+	}
+
+	for _, bits := range []uint64{0x7fffffffffffffff, 0x7ff0000000000000, 0xfff0000000000000} {
+		_, err := lept.StringifyCanonical(lept.NewNumber(math.Float64frombits(bits)))
+		if !errors.Is(err, lept.ErrUnsupportedValue) {
+			t.Errorf("%016x: got error %v want %v", bits, err, lept.ErrUnsupportedValue)
+		}
+	}
+}
+
+func TestCanonicalError(t *testing.T) {
+	_, err := lept.Canonicalize(`{"a": 1, "b": {"c": 2, "c": 3}}`)
+	if !errors.Is(err, lept.ErrDuplicateKey) {
+		t.Errorf("got error %v want %v", err, lept.ErrDuplicateKey)
+	}
+	_, err = lept.Canonicalize(`[1e999]`)
+	if !errors.Is(err, lept.ErrOutOfRange) {
+		t.Errorf("got error %v want %v", err, lept.ErrOutOfRange)
+	}
+	_, err = lept.StringifyCanonical(lept.NewString("\xff"))
+	if !errors.Is(err, lept.ErrUnsupportedValue) {
+		t.Errorf("got error %v want %v", err, lept.ErrUnsupportedValue)
+	}
+	_, err = lept.StringifyCanonical(lept.NewObject().Set("\xfe", lept.NewNull()))
+	if !errors.Is(err, lept.ErrUnsupportedValue) {
+		t.Errorf("got error %v want %v", err, lept.ErrUnsupportedValue)
+	}
+}
@@ -0,0 +1,448 @@
+package lept
+
+import (
+	"slices"
+	"strings"
+)
+
+// Document is a parsed JSON text that keeps everything Parse discards:
+// whitespace, comments, the spelling of keys, numbers and strings, and
+// trailing commas. String returns the input unchanged, and after edits
+// through Node only the edited parts differ.
+type Document struct {
+	Before string // trivia before the root value
+	Root   *Node
+	After  string // trivia after the root value
+}
+
+// Node is a value in a Document. Scalars keep their source text in Raw;
+// arrays and objects hold their entries in Elems.
+type Node struct {
+	Type          Type
+	Raw           string
+	Elems         []*Element
+	End           string // trivia before the closing bracket
+	TrailingComma bool   // the last element is followed by a comma (JSON5)
+}
+
+// Element is an array element or object member with the trivia around it.
+// Comments on their own lines before an element belong to Before; a comment
+// on the same line after it belongs to After, or to Trailing when it follows
+// the comma.
+type Element struct {
+	Before   string
+	Key      string // decoded member name, empty for array elements
+	Sep      string // the text between the key and the value, including ':'
+	Value    *Node
+	After    string
+	Trailing string
+
+	key string // Key as parsed, to detect renames
+	raw string // source text of the key
+}
+
+// ParseDocument parses data into a Document with the options' syntax.
+func (opts ParseOptions) ParseDocument(data string) (*Document, error) {
+	c := newContext(data)
+	c.opts = opts
+	if err := c.checkSize(); err != nil {
+		return nil, c.syntaxError(err)
+	}
+	d := &Document{Before: c.trivia()}
+	root, err := c.parseNode()
+	if err == nil {
+		d.Root = root
+		d.After = c.trivia()
+		if !c.isAtEnd() {
+			err = ErrPluralRoot
+		}
+	}
+	if err != nil {
+		return nil, c.syntaxError(err)
+	}
+	return d, nil
+}
+
+// ParseDocument parses strict JSON into a Document. Use
+// ParseOptions.ParseDocument with JSON5 for documents with comments.
+func ParseDocument(data string) (*Document, error) {
+	return ParseOptions{}.ParseDocument(data)
+}
+
+func (d *Document) String() string {
+	buf := []byte(d.Before)
+	buf = d.Root.appendTo(buf)
+	return string(append(buf, d.After...))
+}
+
+// Value converts the document into a Value tree.
+func (d *Document) Value() (*Value, error) {
+	return d.Root.Value()
+}
+
+func (c *Context) trivia() string {
+	start := c.pos
+	c.parseWhitespace()
+	return c.json[start:c.pos]
+}
+
+// parseNode parses a value into a Node. Like parseValue, it keeps the arrays
+// and objects still open on an explicit stack rather than recursing; the
+// element being parsed is the last one of each.
+func (c *Context) parseNode() (*Node, error) {
+	var stack []*Node
+	var n *Node
+	for {
+		if r := c.peek(); r == '[' || r == '{' {
+			if err := limit(len(stack)+1, c.opts.MaxDepth, ErrDepthLimit); err != nil {
+				return nil, err
+			}
+			n = &Node{Type: TypeArray}
+			if r == '{' {
+				n.Type = TypeObject
+			}
+			c.next()
+			t := c.trivia()
+			if c.peek() != n.closing() {
+				stack = append(stack, n)
+				if err := c.parseElement(n, t); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			c.next()
+			n.End = t
+		} else {
+			start := c.pos
+			v := &Value{}
+			if err := v.parseScalar(c); err != nil {
+				return nil, err
+			}
+			n = &Node{Type: v.Type, Raw: c.json[start:c.pos]}
+		}
+
+		for ; len(stack) > 0; stack = stack[:len(stack)-1] {
+			f := stack[len(stack)-1]
+			e := f.Elems[len(f.Elems)-1]
+			e.Value = n
+			t := c.trivia()
+			if c.peek() == ',' {
+				e.After = t
+				c.next()
+				e.Trailing, t = splitLine(c.trivia())
+				if !c.opts.JSON5 || c.peek() != f.closing() {
+					if err := c.parseElement(f, t); err != nil {
+						return nil, err
+					}
+					break
+				}
+				f.TrailingComma = true
+			} else if c.peek() == f.closing() {
+				e.After, t = splitLine(t)
+			} else {
+				return nil, ErrMissComma
+			}
+			c.next()
+			f.End = t
+			n = f
+		}
+		if len(stack) == 0 {
+			return n, nil
+		}
+	}
+}
+
+// parseElement reads up to the value of the next element of n, which is the
+// member name and colon for objects, and appends the element with the
+// trivia before it.
+func (c *Context) parseElement(n *Node, before string) error {
+	e := &Element{Before: before}
+	if n.Type == TypeArray {
+		if c.isAtEnd() {
+			return ErrMissSquareBracket
+		}
+		if err := limit(len(n.Elems)+1, c.opts.MaxArrayLen, ErrArrayLimit); err != nil {
+			return err
+		}
+		n.Elems = append(n.Elems, e)
+		return nil
+	}
+
+	if c.isAtEnd() {
+		return ErrMissCurlyBracket
+	}
+	if err := limit(len(n.Elems)+1, c.opts.MaxObjectMembers, ErrObjectLimit); err != nil {
+		return err
+	}
+	start := c.pos
+	k, err := (&Value{}).parseKey(c)
+	if err != nil {
+		return err
+	}
+	e.Key, e.key, e.raw = k, k, c.json[start:c.pos]
+
+	start = c.pos
+	c.parseWhitespace()
+	if c.peek() != ':' {
+		return ErrMissColon
+	}
+	c.next()
+	c.parseWhitespace()
+	e.Sep = c.json[start:c.pos]
+	n.Elems = append(n.Elems, e)
+	return nil
+}
+
+func (n *Node) closing() rune {
+	if n.Type == TypeObject {
+		return '}'
+	}
+	return ']'
+}
+
+// splitLine splits trivia after the first line break that is not inside a
+// comment, so that a comment ending the line stays with what precedes it.
+func splitLine(t string) (string, string) {
+	for i := 0; i < len(t); i++ {
+		switch {
+		case t[i] == '\n':
+			return t[:i+1], t[i+1:]
+		case strings.HasPrefix(t[i:], "//"):
+			if j := strings.IndexByte(t[i:], '\n'); j > 0 {
+				i += j - 1
+			} else {
+				i = len(t)
+			}
+		case strings.HasPrefix(t[i:], "/*"):
+			i += strings.Index(t[i+2:], "*/") + 3
+		}
+	}
+	return "", t
+}
+
+func (n *Node) String() string {
+	return string(n.appendTo(nil))
+}
+
+func (n *Node) appendTo(buf []byte) []byte {
+	var open, close byte
+	switch n.Type {
+	case TypeArray:
+		open, close = '[', ']'
+	case TypeObject:
+		open, close = '{', '}'
+	default:
+		return append(buf, n.Raw...)
+	}
+
+	buf = append(buf, open)
+	for i, e := range n.Elems {
+		buf = append(buf, e.Before...)
+		if n.Type == TypeObject {
+			if e.Key == e.key && e.raw != "" {
+				buf = append(buf, e.raw...)
+			} else {
+				buf = appendString(buf, e.Key)
+			}
+			buf = append(buf, e.Sep...)
+		}
+		buf = e.Value.appendTo(buf)
+		buf = append(buf, e.After...)
+		if i < len(n.Elems)-1 || n.TrailingComma {
+			buf = append(buf, ',')
+			buf = append(buf, e.Trailing...)
+		}
+	}
+	buf = append(buf, n.End...)
+	return append(buf, close)
+}
+
+// Value converts the node into a Value tree.
+func (n *Node) Value() (*Value, error) {
+	switch n.Type {
+	case TypeArray:
+		v := NewArray()
+		for _, e := range n.Elems {
+			ev, err := e.Value.Value()
+			if err != nil {
+				return nil, err
+			}
+			v.Append(ev)
+		}
+		return v, nil
+	case TypeObject:
+		v := NewObject()
+		for _, e := range n.Elems {
+			ev, err := e.Value.Value()
+			if err != nil {
+				return nil, err
+			}
+			v.Set(e.Key, ev)
+		}
+		return v, nil
+	default:
+		return ParseOptions{JSON5: true}.Parse(n.Raw)
+	}
+}
+
+func newNode(v *Value) (*Node, error) {
+	s, err := Stringify(v)
+	if err != nil {
+		return nil, err
+	}
+	d, err := ParseDocument(s)
+	if err != nil {
+		return nil, err
+	}
+	return d.Root, nil
+}
+
+// Get returns the value of the last member named key, or nil.
+func (n *Node) Get(key string) *Node {
+	if i := n.member(key); i >= 0 {
+		return n.Elems[i].Value
+	}
+	return nil
+}
+
+func (n *Node) Index(i int) *Node {
+	if n.Type != TypeArray || i < 0 || i >= len(n.Elems) {
+		return nil
+	}
+	return n.Elems[i].Value
+}
+
+func (n *Node) member(key string) int {
+	if n.Type != TypeObject {
+		return -1
+	}
+	for i := len(n.Elems) - 1; i >= 0; i-- {
+		if n.Elems[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetValue replaces the node with v, written compactly. The trivia around
+// the node is kept.
+func (n *Node) SetValue(v *Value) error {
+	nn, err := newNode(v)
+	if err != nil {
+		return err
+	}
+	*n = *nn
+	return nil
+}
+
+// Set replaces the value of the member named key, or appends a new member
+// laid out like the one before it.
+func (n *Node) Set(key string, v *Value) error {
+	if n.Type != TypeObject {
+		return ErrMismatchType
+	}
+	if i := n.member(key); i >= 0 {
+		return n.Elems[i].Value.SetValue(v)
+	}
+	nn, err := newNode(v)
+	if err != nil {
+		return err
+	}
+	n.insert(&Element{Key: key, Value: nn})
+	return nil
+}
+
+// Append adds v as the last array element, laid out like the one before it.
+func (n *Node) Append(v *Value) error {
+	if n.Type != TypeArray {
+		return ErrMismatchType
+	}
+	nn, err := newNode(v)
+	if err != nil {
+		return err
+	}
+	n.insert(&Element{Value: nn})
+	return nil
+}
+
+func (n *Node) insert(e *Element) {
+	if len(n.Elems) == 0 {
+		if n.Type == TypeObject {
+			e.Sep = ": "
+		}
+		if strings.TrimSpace(n.End) == "" && !strings.Contains(n.End, "\n") {
+			n.End = ""
+		}
+		n.Elems = append(n.Elems, e)
+		return
+	}
+
+	last := n.Elems[len(n.Elems)-1]
+	e.Before = last.Before[strings.LastIndexByte(last.Before, '\n')+1:]
+	if len(n.Elems) == 1 && !strings.Contains(last.Before, "\n") {
+		e.Before = " "
+	}
+	e.Sep = last.Sep
+	if strings.Contains(e.Sep, "/") {
+		e.Sep = ": "
+	}
+	if n.TrailingComma {
+		e.Trailing = lineEnd(last.Trailing)
+	} else {
+		// the comma now goes after the old last element, before any
+		// comment ending its line
+		e.After = lineEnd(last.After)
+		last.Trailing, last.After = last.After, ""
+	}
+	n.Elems = append(n.Elems, e)
+}
+
+func lineEnd(s string) string {
+	switch {
+	case strings.HasSuffix(s, "\r\n"):
+		return "\r\n"
+	case strings.HasSuffix(s, "\n"):
+		return "\n"
+	}
+	return ""
+}
+
+// Remove deletes every member named key and reports whether there was one.
+func (n *Node) Remove(key string) bool {
+	found := false
+	for i := n.member(key); i >= 0; i = n.member(key) {
+		n.remove(i)
+		found = true
+	}
+	return found
+}
+
+func (n *Node) RemoveIndex(i int) bool {
+	if n.Type != TypeArray || i < 0 || i >= len(n.Elems) {
+		return false
+	}
+	n.remove(i)
+	return true
+}
+
+func (n *Node) remove(i int) {
+	e := n.Elems[i]
+	n.Elems = slices.Delete(n.Elems, i, i+1)
+	switch {
+	case len(n.Elems) == 0:
+	case i == len(n.Elems) && !n.TrailingComma:
+		// the new last element loses its comma, keep what followed it
+		prev := n.Elems[i-1]
+		prev.After += prev.Trailing
+		prev.Trailing = ""
+	case i == 0:
+		// the new first element takes over the line break after the
+		// opening bracket
+		next := n.Elems[0]
+		if strings.Contains(next.Before, "\n") {
+			next.Before = e.Before[:strings.LastIndexByte(e.Before, '\n')+1] + next.Before
+		} else {
+			next.Before = e.Before
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package lept_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func parseDocument(t *testing.T, json string) *lept.Document {
+	t.Helper()
+	d, err := lept.ParseOptions{JSON5: true}.ParseDocument(json)
+	if err != nil {
+		t.Fatalf("parse %s failed: %v", json, err)
+	}
+	return d
+}
+
+const config = `// settings
+{
+  "name": "lept", // the name
+  /* the version */
+  version :  1.0e0,
+  'tags': [ "a",  0x1F ,],
+
+  "empty": {  },
+}
+`
+
+func TestDocumentRoundTrip(t *testing.T) {
+	for _, json := range []string{
+		config,
+		`1`,
+		" \n\"a\\u0041\"\t",
+		`[]`,
+		`[1,2 ,3]`,
+		"{\r\n\t\"a\": [\r\n\t\t1, // one\r\n\t\t2\r\n\t]\r\n}",
+		`[/* a */ 1 /* b */, /* c */ 2 /* d */]`,
+	} {
+		assertValue(t, parseDocument(t, json).String(), json)
+	}
+}
+
+func TestDocumentValue(t *testing.T) {
+	v, err := parseDocument(t, config).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := lept.Stringify(v)
+	assertValue(t, got, `{"name":"lept","version":1.0e0,"tags":["a",31],"empty":{}}`)
+}
+
+func TestDocumentError(t *testing.T) {
+	tests := []struct {
+		json string
+		err  error
+	}{
+		{`[1,]`, lept.ErrUnexpectedChar},
+		{`// c` + "\n1", lept.ErrUnexpectedChar},
+		{`{"a" 1}`, lept.ErrMissColon},
+		{`[1 2]`, lept.ErrMissComma},
+		{`[1`, lept.ErrMissComma},
+		{`{"a":1,`, lept.ErrMissCurlyBracket},
+		{`1 2`, lept.ErrPluralRoot},
+	}
+	for _, tt := range tests {
+		_, err := lept.ParseDocument(tt.json)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.json, err, tt.err)
+		}
+	}
+}
+
+func TestDocumentEdit(t *testing.T) {
+	d := parseDocument(t, config)
+	d.Root.Get("tags").SetValue(lept.NewArray().Append(lept.NewString("b")))
+	d.Root.Set("version", lept.NewNumber(2))
+	d.Root.Set("new", lept.NewBool(true))
+	d.Root.Get("empty").Set("x", lept.NewNull())
+	d.Root.Remove("name")
+	assertValue(t, d.String(), `// settings
+{
+  /* the version */
+  version :  2,
+  'tags': ["b"],
+
+  "empty": {"x": null},
+  "new": true,
+}
+`)
+}
+
+func TestDocumentAppend(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		{`[]`, `[1]`},
+		{`[0]`, `[0, 1]`},
+		{`[0,0]`, `[0,0,1]`},
+		{`[ 0 ]`, `[ 0, 1 ]`},
+		{"[\n  0\n]", "[\n  0,\n  1\n]"},
+		{"[\n  0, // zero\n  0 // zero\n]", "[\n  0, // zero\n  0, // zero\n  1\n]"},
+		{"[\r\n  0,\r\n]", "[\r\n  0,\r\n  1,\r\n]"},
+	}
+	for _, tt := range tests {
+		d := parseDocument(t, tt.json)
+		if err := d.Root.Append(lept.NewNumber(1)); err != nil {
+			t.Fatal(err)
+		}
+		assertValue(t, d.String(), tt.want)
+	}
+}
+
+func TestDocumentRemove(t *testing.T) {
+	tests := []struct {
+		json  string
+		index int
+		want  string
+	}{
+		{`[0]`, 0, `[]`},
+		{`[0, 1, 2]`, 0, `[1, 2]`},
+		{`[0, 1, 2]`, 1, `[0, 2]`},
+		{`[0, 1, 2]`, 2, `[0, 1]`},
+		{"[\n  0, // zero\n  // one\n  1 // one\n]", 0, "[\n  // one\n  1 // one\n]"},
+		{"[\n  0, // zero\n  // one\n  1 // one\n]", 1, "[\n  0 // zero\n]"},
+		{"[\n  0,\n  1,\n]", 1, "[\n  0,\n]"},
+	}
+	for _, tt := range tests {
+		d := parseDocument(t, tt.json)
+		if !d.Root.RemoveIndex(tt.index) {
+			t.Fatalf("%s: remove %d failed", tt.json, tt.index)
+		}
+		assertValue(t, d.String(), tt.want)
+	}
+	if parseDocument(t, `{}`).Root.RemoveIndex(0) {
+		t.Error("removed from an object")
+	}
+}
+
+func TestDocumentRename(t *testing.T) {
+	d := parseDocument(t, `{a: 1, 'b': 2}`)
+	d.Root.Elems[0].Key = "a b"
+	assertValue(t, d.String(), `{"a b": 1, 'b': 2}`)
+	if err := d.Root.Append(lept.NewNull()); !errors.Is(err, lept.ErrMismatchType) {
+		t.Errorf("got %v, want %v", err, lept.ErrMismatchType)
+	}
+}
@@ -0,0 +1,154 @@
+package lept_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wasuppu/lept"
+)
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+)
+
+var levelNames = []string{"debug", "info", "warn"}
+
+func (l Level) MarshalLept() (*lept.Value, error) {
+	if l < 0 || int(l) >= len(levelNames) {
+		return nil, fmt.Errorf("invalid level %d", l)
+	}
+	return lept.NewString(levelNames[l]), nil
+}
+
+func (l *Level) UnmarshalLept(v *lept.Value) error {
+	if v.Type == lept.TypeNull {
+		return nil
+	}
+	for i, name := range levelNames {
+		if v.STRING() == name {
+			*l = Level(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid level %v", v)
+}
+
+type UUID [16]byte
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(u[:])), nil
+}
+
+func (u *UUID) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	if len(b) != len(u) {
+		return errors.New("invalid uuid length")
+	}
+	copy(u[:], b)
+	return nil
+}
+
+type Event struct {
+	ID      UUID           `json:"id"`
+	At      time.Time      `json:"at"`
+	Level   Level          `json:"level"`
+	Levels  []Level        `json:"levels"`
+	Owner   *UUID          `json:"owner"`
+	Seen    map[UUID]Level `json:"seen"`
+	Updated *time.Time     `json:"updated"`
+	Any     lept.Marshaler `json:"any"`
+	Raw     json2Unmarshal `json:"raw"`
+	Pointer *Level         `json:"pointer"`
+}
+
+// json2Unmarshal only implements encoding/json.Unmarshaler.
+type json2Unmarshal struct {
+	data string
+}
+
+func (j *json2Unmarshal) UnmarshalJSON(data []byte) error {
+	j.data = string(data)
+	return nil
+}
+
+func TestCustomMarshaler(t *testing.T) {
+	id := UUID{0: 0xab, 15: 0xcd}
+	at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	e := Event{
+		ID:     id,
+		At:     at,
+		Level:  Warn,
+		Levels: []Level{Debug, Info},
+		Seen:   map[UUID]Level{id: Info},
+		Any:    Info,
+	}
+
+	s, err := lept.MarshalString(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"ab0000000000000000000000000000cd","at":"2024-05-06T07:08:09Z","level":"warn","levels":["debug","info"],"owner":null,"seen":{"ab0000000000000000000000000000cd":"info"},"updated":null,"any":"info","raw":{},"pointer":null}`
+	assertValue(t, s, want)
+
+	if _, err := lept.Marshal(Level(9)); err == nil || !strings.Contains(err.Error(), "invalid level") {
+		t.Errorf("got error %v want invalid level", err)
+	}
+}
+
+func TestCustomUnmarshaler(t *testing.T) {
+	data := `{
+		"id": "ab0000000000000000000000000000cd",
+		"at": "2024-05-06T07:08:09Z",
+		"level": "warn",
+		"levels": ["debug", "info"],
+		"owner": "00000000000000000000000000000001",
+		"seen": {"ab0000000000000000000000000000cd": "info"},
+		"updated": null,
+		"raw": {"a": [1, "x"]},
+		"pointer": "info"
+	}`
+	v, err := lept.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := Event{}
+	if err := lept.Unmarshal(v, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	id := UUID{0: 0xab, 15: 0xcd}
+	assertValue(t, got.ID, id)
+	assertValue(t, got.At.Equal(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)), true)
+	assertValue(t, got.Level, Warn)
+	if !reflect.DeepEqual(got.Levels, []Level{Debug, Info}) {
+		t.Errorf("got %v", got.Levels)
+	}
+	assertValue(t, *got.Owner, UUID{15: 1})
+	assertValue(t, got.Seen[id], Info)
+	assertValue(t, got.Updated, nil)
+	assertValue(t, got.Raw.data, `{"a":[1,"x"]}`)
+	assertValue(t, *got.Pointer, Info)
+
+	for _, json := range []string{`{"level": "fatal"}`, `{"id": "zz"}`, `{"id": 1}`, `{"at": "yesterday"}`} {
+		v, err := lept.Parse(json)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := lept.Unmarshal(v, &Event{}); err == nil {
+			t.Errorf("unmarshal %s: expected error", json)
+		}
+	}
+}
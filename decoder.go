@@ -0,0 +1,257 @@
+package lept
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const minRead = 512
+
+type Decoder struct {
+	r     io.Reader
+	buf   []byte
+	scanp int   // start of unread data in buf
+	err   error // sticky error from r
+
+	base int // input offset of buf[0]
+	line int // newlines before scanp
+	col  int // runes between the last newline and scanp
+
+	opts      DecodeOptions
+	parseOpts ParseOptions
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the next top-level value from the input, so a stream of
+// concatenated or newline-delimited values is read by calling it repeatedly
+// until it returns io.EOF.
+func (d *Decoder) Decode() (*Value, error) {
+	n, err := d.readValue()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := d.parseOpts.Parse(string(d.buf[d.scanp : d.scanp+n]))
+	if se, ok := err.(*SyntaxError); ok {
+		d.locate(se)
+	}
+	d.advance(n)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Unmarshal reads the next value from the input and stores it in v, applying
+// the options set on the Decoder.
+func (d *Decoder) Unmarshal(v any) error {
+	parsed, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	return d.opts.Unmarshal(parsed, v)
+}
+
+// SetParseOptions sets the syntax and limits used to parse each value. The
+// MaxBytes limit applies to each value and is enforced while reading it, so
+// an oversized value fails before it is buffered whole. ZeroCopy is ignored:
+// the Decoder reuses its buffer, so values are always copied out of it.
+func (d *Decoder) SetParseOptions(opts ParseOptions) {
+	d.parseOpts = opts
+}
+
+func (d *Decoder) DisallowUnknownFields() {
+	d.opts.DisallowUnknownFields = true
+}
+
+func (d *Decoder) DisallowDuplicateKeys() {
+	d.opts.DisallowDuplicateKeys = true
+}
+
+func (d *Decoder) UseNumber() {
+	d.opts.UseNumber = true
+}
+
+// locate turns the position of se, relative to the value at scanp, into a
+// position in the whole input.
+func (d *Decoder) locate(se *SyntaxError) {
+	se.Offset += d.base + d.scanp
+	if se.Line == 1 {
+		se.Column += d.col
+	}
+	se.Line += d.line
+}
+
+func (d *Decoder) advance(n int) {
+	for _, b := range d.buf[d.scanp : d.scanp+n] {
+		if b == '\n' {
+			d.line++
+			d.col = 0
+		} else if utf8.RuneStart(b) {
+			d.col++
+		}
+	}
+	d.scanp += n
+}
+
+// readValue finds the extent of the next value in buf, reading more input as
+// needed. It only balances brackets and quotes, leaving validation to Parse.
+// With JSON5 it also skips comments and knows single-quoted strings.
+func (d *Decoder) readValue() (int, error) {
+	json5 := d.parseOpts.JSON5
+	for {
+		for d.scanp < len(d.buf) {
+			if isSpace(d.buf[d.scanp]) {
+				d.advance(1)
+				continue
+			}
+			if json5 && d.buf[d.scanp] == '/' {
+				if n, more := commentLen(d.buf[d.scanp:], d.err != nil); n > 0 {
+					d.advance(n)
+					continue
+				} else if more {
+					break
+				}
+			}
+			return d.scanValue()
+		}
+		if d.err != nil && d.scanp == len(d.buf) {
+			return 0, d.err
+		}
+		d.refill()
+	}
+}
+
+// scanValue finds the extent of the value starting at scanp.
+func (d *Decoder) scanValue() (int, error) {
+	json5 := d.parseOpts.JSON5
+	first := d.buf[d.scanp]
+	quote := byte(0)
+	if first == '"' || json5 && first == '\'' {
+		quote = first
+	}
+	scalar := first != '{' && first != '[' && quote == 0
+	escaped := false
+	depth := 0
+	if first == '{' || first == '[' {
+		depth = 1
+	}
+	ends := `,:[]{}"`
+	if json5 {
+		ends += `'/`
+	}
+
+	i := d.scanp + 1
+	for {
+	scan:
+		for ; i < len(d.buf); i++ {
+			b := d.buf[i]
+			switch {
+			case quote != 0:
+				if escaped {
+					escaped = false
+				} else if b == '\\' {
+					escaped = true
+				} else if b == quote {
+					quote = 0
+					if depth == 0 {
+						return i + 1 - d.scanp, nil
+					}
+				}
+			case scalar:
+				if isSpace(b) || strings.IndexByte(ends, b) >= 0 {
+					return i - d.scanp, nil
+				}
+			case b == '"', json5 && b == '\'':
+				quote = b
+			case json5 && b == '/':
+				n, more := commentLen(d.buf[i:], d.err != nil)
+				if more {
+					break scan
+				}
+				i += max(n-1, 0)
+			case b == '{' || b == '[':
+				depth++
+			case b == '}' || b == ']':
+				depth--
+				if depth == 0 {
+					return i + 1 - d.scanp, nil
+				}
+			}
+		}
+
+		if max := d.parseOpts.MaxBytes; max > 0 && i-d.scanp > max {
+			se := newSyntaxError(string(d.buf[d.scanp:i]), max, &LimitError{ErrSizeLimit, max})
+			d.locate(se)
+			return 0, se
+		}
+		if d.err != nil {
+			if d.err == io.EOF {
+				if scalar {
+					return i - d.scanp, nil
+				}
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, d.err
+		}
+		// refill may move the unread data to the front of buf
+		i -= d.scanp
+		d.refill()
+		i += d.scanp
+	}
+}
+
+// commentLen returns the length of the JSON5 comment at the start of b, or 0
+// if there is none. more reports that b ends before this can be told and
+// more input is needed, which is never the case at EOF.
+func commentLen(b []byte, eof bool) (n int, more bool) {
+	if len(b) < 2 {
+		return 0, !eof
+	}
+	switch {
+	case b[1] == '/':
+		if end := bytes.IndexAny(b, "\n\r\u2028\u2029"); end >= 0 {
+			return end, false
+		}
+		if eof {
+			return len(b), false
+		}
+		return 0, true
+	case b[1] == '*':
+		if end := bytes.Index(b[2:], []byte("*/")); end >= 0 {
+			return end + 4, false
+		}
+		return 0, !eof
+	}
+	return 0, false
+}
+
+func (d *Decoder) refill() {
+	if d.scanp > 0 {
+		d.base += d.scanp
+		n := copy(d.buf, d.buf[d.scanp:])
+		d.buf = d.buf[:n]
+		d.scanp = 0
+	}
+
+	if cap(d.buf)-len(d.buf) < minRead {
+		buf := make([]byte, len(d.buf), 2*cap(d.buf)+minRead)
+		copy(buf, d.buf)
+		d.buf = buf
+	}
+
+	n, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+	d.buf = d.buf[:len(d.buf)+n]
+	if err != nil {
+		d.err = err
+	}
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
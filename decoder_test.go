@@ -0,0 +1,195 @@
+package lept_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/wasuppu/lept"
+)
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"whitespace", " \n\t ", nil},
+		{"ndjson", "{\"a\":1}\n{\"b\":[2,3]}\n\"x\"\n", []string{`{"a":1}`, `{"b":[2,3]}`, `"x"`}},
+		{"concatenated", `{"a":1}{"b":2}[3]"x"4 true null[]`, []string{`{"a":1}`, `{"b":2}`, `[3]`, `"x"`, `4`, `true`, `null`, `[]`}},
+		{"brackets in strings", `["]", "}\"{", "\\"] {"k}": "["}`, []string{`["]","}\"{","\\"]`, `{"k}":"["}`}},
+		{"scalar at end", `1 -2.5e3`, []string{`1`, `-2.5e3`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testDecoder(t, strings.NewReader(tt.in), tt.want)
+			testDecoder(t, iotest.OneByteReader(strings.NewReader(tt.in)), tt.want)
+			testDecoder(t, iotest.DataErrReader(strings.NewReader(tt.in)), tt.want)
+		})
+	}
+}
+
+func testDecoder(t *testing.T, r io.Reader, want []string) {
+	t.Helper()
+	dec := lept.NewDecoder(r)
+	for _, w := range want {
+		v, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		got, err := lept.Stringify(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertValue(t, got, w)
+	}
+	for range 2 {
+		if _, err := dec.Decode(); err != io.EOF {
+			t.Fatalf("got error %v want %v", err, io.EOF)
+		}
+	}
+}
+
+func TestDecoderLarge(t *testing.T) {
+	sb := &strings.Builder{}
+	sb.WriteString("[")
+	for i := range 10000 {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(`{"id":1,"name":"lept"}`)
+	}
+	sb.WriteString("]\n[]")
+
+	dec := lept.NewDecoder(strings.NewReader(sb.String()))
+	v, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, len(v.ARRAY()), 10000)
+	v, err = dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, len(v.ARRAY()), 0)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	for _, in := range []string{`{"a":1`, `["abc`, `"abc\"`} {
+		_, err := lept.NewDecoder(strings.NewReader(in)).Decode()
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("decode %q: got error %v want %v", in, err, io.ErrUnexpectedEOF)
+		}
+	}
+
+	dec := lept.NewDecoder(strings.NewReader(`[1,,2] {"a":1}`))
+	if _, err := dec.Decode(); err == nil {
+		t.Error("decode invalid value: expected error")
+	}
+	if _, err := dec.Decode(); err != nil {
+		t.Errorf("decode after invalid value failed: %v", err)
+	}
+
+	readErr := errors.New("read failed")
+	dec = lept.NewDecoder(io.MultiReader(strings.NewReader(`1 [`), iotest.ErrReader(readErr)))
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.Decode(); err != readErr {
+		t.Errorf("got error %v want %v", err, readErr)
+	}
+}
+
+func TestDecoderSyntaxError(t *testing.T) {
+	in := "{\"a\": 1}\n{\"b\": 2}\n  [1,\n   2 3]"
+	dec := lept.NewDecoder(iotest.OneByteReader(strings.NewReader(in)))
+	for range 2 {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := dec.Decode()
+	var se *lept.SyntaxError
+	if !errors.As(err, &se) || !errors.Is(err, lept.ErrMissComma) {
+		t.Fatalf("got error %v want syntax error %v", err, lept.ErrMissComma)
+	}
+	assertValue(t, se.Offset, 29)
+	assertValue(t, se.Line, 4)
+	assertValue(t, se.Column, 6)
+
+	dec = lept.NewDecoder(strings.NewReader("1 [tru]"))
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	_, err = dec.Decode()
+	if !errors.As(err, &se) {
+		t.Fatalf("got error %v want *lept.SyntaxError", err)
+	}
+	assertValue(t, se.Offset, 3)
+	assertValue(t, se.Line, 1)
+	assertValue(t, se.Column, 4)
+}
+
+// endless reads "1," forever, counting the bytes read.
+type endless struct{ n int }
+
+func (r *endless) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = "1,"[(r.n+i)%2]
+	}
+	r.n += len(p)
+	return len(p), nil
+}
+
+func TestDecoderLimits(t *testing.T) {
+	r := &endless{}
+	dec := lept.NewDecoder(io.MultiReader(strings.NewReader("[1]\n["), r))
+	dec.SetParseOptions(lept.ParseOptions{MaxBytes: 1 << 16})
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := dec.Decode()
+	var se *lept.SyntaxError
+	if !errors.As(err, &se) || !errors.Is(err, lept.ErrSizeLimit) {
+		t.Fatalf("got error %v want %v", err, lept.ErrSizeLimit)
+	}
+	assertValue(t, se.Offset, 4+1<<16)
+	assertValue(t, se.Line, 2)
+	if r.n > 1<<18 {
+		t.Errorf("read %d bytes of an oversized value", r.n)
+	}
+
+	dec = lept.NewDecoder(strings.NewReader(`[1] [[1]]`))
+	dec.SetParseOptions(lept.ParseOptions{MaxDepth: 1})
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.Decode(); !errors.Is(err, lept.ErrDepthLimit) {
+		t.Errorf("got error %v want %v", err, lept.ErrDepthLimit)
+	}
+}
+
+func TestDecoderJSON5(t *testing.T) {
+	in := "{'a': '}'} 1// c\n// c\n{a: 1} /* ] */ [1, /* ] */ 'x]' // ]\n] 'y' /* end */ //"
+	want := []string{`{"a":"}"}`, `1`, `{"a":1}`, `[1,"x]"]`, `"y"`}
+	for _, r := range []io.Reader{strings.NewReader(in), iotest.OneByteReader(strings.NewReader(in))} {
+		dec := lept.NewDecoder(r)
+		dec.SetParseOptions(lept.ParseOptions{JSON5: true})
+		for _, w := range want {
+			v, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := lept.Stringify(v)
+			assertValue(t, got, w)
+		}
+		if _, err := dec.Decode(); err != io.EOF {
+			t.Errorf("got error %v want %v", err, io.EOF)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package lept
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+type EncodeOptions struct {
+	Prefix string // written at the start of every line after the first
+	Indent string // one level of indentation; empty means compact output
+
+	SortKeys        bool // write object members ordered by key instead of insertion order
+	CompactArrays   bool // keep arrays that only hold scalars on a single line
+	Width           int  // maximum line width for compact arrays, 0 for no limit
+	TrailingNewline bool
+}
+
+var DefaultEncodeOptions = EncodeOptions{Indent: "    ", TrailingNewline: true}
+
+type Encoder struct {
+	w    io.Writer
+	opts EncodeOptions
+}
+
+func NewEncoder(w io.Writer, opts EncodeOptions) *Encoder {
+	return &Encoder{w, opts}
+}
+
+func (enc *Encoder) Encode(v *Value) error {
+	e := encodeState{opts: enc.opts}
+	if err := e.value(v, 0); err != nil {
+		return err
+	}
+	if enc.opts.TrailingNewline {
+		e.buf = append(e.buf, '\n')
+	}
+	_, err := enc.w.Write(e.buf)
+	return err
+}
+
+func Format(src string, opts ...EncodeOptions) (string, error) {
+	v, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	o := DefaultEncodeOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	sb := &strings.Builder{}
+	if err := NewEncoder(sb, o).Encode(v); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+type encodeState struct {
+	buf  []byte
+	opts EncodeOptions
+}
+
+func (e *encodeState) pretty() bool {
+	return e.opts.Indent != "" || e.opts.Prefix != ""
+}
+
+func (e *encodeState) newline(depth int) {
+	e.buf = append(e.buf, '\n')
+	e.buf = append(e.buf, e.opts.Prefix...)
+	for range depth {
+		e.buf = append(e.buf, e.opts.Indent...)
+	}
+}
+
+func (e *encodeState) value(v *Value, depth int) (err error) {
+	if v == nil {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+
+	switch v.Type {
+	case TypeNull:
+		e.buf = append(e.buf, "null"...)
+	case TypeTrue:
+		e.buf = append(e.buf, "true"...)
+	case TypeFalse:
+		e.buf = append(e.buf, "false"...)
+	case TypeNumber:
+		e.buf, err = appendNumber(e.buf, v.U)
+	case TypeString:
+		s, ok := v.U.(string)
+		if !ok {
+			return ErrMismatchType
+		}
+		e.buf = appendString(e.buf, s)
+	case TypeArray:
+		arr, ok := v.U.(Array)
+		if !ok {
+			return ErrMismatchType
+		}
+		err = e.array(arr, depth)
+	case TypeObject:
+		obj, ok := v.U.(Object)
+		if !ok {
+			return ErrMismatchType
+		}
+		err = e.object(obj, depth)
+	default:
+		err = errUnsupportedType(v)
+	}
+	return
+}
+
+func (e *encodeState) array(arr Array, depth int) error {
+	if len(arr) == 0 {
+		e.buf = append(e.buf, "[]"...)
+		return nil
+	}
+
+	if e.pretty() && e.opts.CompactArrays && isScalars(arr) {
+		ok, err := e.inlineArray(arr)
+		if ok || err != nil {
+			return err
+		}
+	}
+
+	e.buf = append(e.buf, '[')
+	for i, elem := range arr {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if e.pretty() {
+			e.newline(depth + 1)
+		}
+		if err := e.value(elem, depth+1); err != nil {
+			return err
+		}
+	}
+	if e.pretty() {
+		e.newline(depth)
+	}
+	e.buf = append(e.buf, ']')
+	return nil
+}
+
+// inlineArray writes arr on the current line, reporting false and leaving the
+// buffer untouched when the result would exceed the configured width.
+func (e *encodeState) inlineArray(arr Array) (bool, error) {
+	start := len(e.buf)
+	e.buf = append(e.buf, '[')
+	for i, elem := range arr {
+		if i > 0 {
+			e.buf = append(e.buf, ", "...)
+		}
+		if err := e.value(elem, 0); err != nil {
+			return false, err
+		}
+	}
+	e.buf = append(e.buf, ']')
+
+	if e.opts.Width > 0 {
+		line := e.buf[bytes.LastIndexByte(e.buf[:start], '\n')+1:]
+		if utf8.RuneCount(line) > e.opts.Width {
+			e.buf = e.buf[:start]
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (e *encodeState) object(obj Object, depth int) error {
+	if len(obj) == 0 {
+		e.buf = append(e.buf, "{}"...)
+		return nil
+	}
+
+	if e.opts.SortKeys {
+		obj = slices.Clone(obj)
+		slices.SortStableFunc(obj, func(a, b Member) int {
+			return strings.Compare(a.K, b.K)
+		})
+	}
+
+	e.buf = append(e.buf, '{')
+	for i, m := range obj {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if e.pretty() {
+			e.newline(depth + 1)
+		}
+		e.buf = appendString(e.buf, m.K)
+		e.buf = append(e.buf, ':')
+		if e.pretty() {
+			e.buf = append(e.buf, ' ')
+		}
+		if err := e.value(m.V, depth+1); err != nil {
+			return err
+		}
+	}
+	if e.pretty() {
+		e.newline(depth)
+	}
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+func isScalars(arr Array) bool {
+	for _, elem := range arr {
+		if elem != nil && (elem.Type == TypeArray || elem.Type == TypeObject) {
+			return false
+		}
+	}
+	return true
+}
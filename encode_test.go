@@ -0,0 +1,120 @@
+package lept_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func TestEncoder(t *testing.T) {
+	src := `{"title":"Design Patterns","author":["Erich Gamma","Richard Helm"],"year":2009,"publisher":{"Company":"Pearson Education","Country":"India"},"tags":[],"extra":{}}`
+
+	tests := []struct {
+		name string
+		opts lept.EncodeOptions
+		want string
+	}{
+		{"compact", lept.EncodeOptions{}, src},
+		{"indent", lept.EncodeOptions{Indent: "  "}, `{
+  "title": "Design Patterns",
+  "author": [
+    "Erich Gamma",
+    "Richard Helm"
+  ],
+  "year": 2009,
+  "publisher": {
+    "Company": "Pearson Education",
+    "Country": "India"
+  },
+  "tags": [],
+  "extra": {}
+}`},
+		{"prefix", lept.EncodeOptions{Prefix: "> ", Indent: "\t", TrailingNewline: true}, "{\n" +
+			"> \t\"title\": \"Design Patterns\",\n" +
+			"> \t\"author\": [\n" +
+			"> \t\t\"Erich Gamma\",\n" +
+			"> \t\t\"Richard Helm\"\n" +
+			"> \t],\n" +
+			"> \t\"year\": 2009,\n" +
+			"> \t\"publisher\": {\n" +
+			"> \t\t\"Company\": \"Pearson Education\",\n" +
+			"> \t\t\"Country\": \"India\"\n" +
+			"> \t},\n" +
+			"> \t\"tags\": [],\n" +
+			"> \t\"extra\": {}\n" +
+			"> }\n"},
+		{"sort keys", lept.EncodeOptions{SortKeys: true}, `{"author":["Erich Gamma","Richard Helm"],"extra":{},"publisher":{"Company":"Pearson Education","Country":"India"},"tags":[],"title":"Design Patterns","year":2009}`},
+		{"compact arrays", lept.EncodeOptions{Indent: "  ", CompactArrays: true}, `{
+  "title": "Design Patterns",
+  "author": ["Erich Gamma", "Richard Helm"],
+  "year": 2009,
+  "publisher": {
+    "Company": "Pearson Education",
+    "Country": "India"
+  },
+  "tags": [],
+  "extra": {}
+}`},
+		{"compact arrays width", lept.EncodeOptions{Indent: "  ", CompactArrays: true, Width: 30}, `{
+  "title": "Design Patterns",
+  "author": [
+    "Erich Gamma",
+    "Richard Helm"
+  ],
+  "year": 2009,
+  "publisher": {
+    "Company": "Pearson Education",
+    "Country": "India"
+  },
+  "tags": [],
+  "extra": {}
+}`},
+	}
+
+	v, err := lept.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+			if err := lept.NewEncoder(sb, tt.opts).Encode(v); err != nil {
+				t.Fatal(err)
+			}
+			assertValue(t, sb.String(), tt.want)
+		})
+	}
+}
+
+func TestEncoderBuilder(t *testing.T) {
+	v := lept.NewObject().
+		Set("name", lept.NewString("lept")).
+		Set("ports", lept.NewArray().Append(lept.NewNumber(80), lept.NewNumber(443))).
+		Set("debug", lept.NewBool(false))
+
+	sb := &strings.Builder{}
+	opts := lept.EncodeOptions{Indent: "  ", SortKeys: true, CompactArrays: true, TrailingNewline: true}
+	if err := lept.NewEncoder(sb, opts).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, sb.String(), "{\n  \"debug\": false,\n  \"name\": \"lept\",\n  \"ports\": [80, 443]\n}\n")
+}
+
+func TestFormat(t *testing.T) {
+	got, err := lept.Format(` { "a" : [ 1 , 2 ] , "b" : { } } `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, got, "{\n    \"a\": [\n        1,\n        2\n    ],\n    \"b\": {}\n}\n")
+
+	got, err = lept.Format(` [ 1 , 2 ] `, lept.EncodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, got, "[1,2]")
+
+	if _, err := lept.Format(`[1,`); err == nil {
+		t.Error("format invalid json: expected error")
+	}
+}
@@ -0,0 +1,232 @@
+package lept
+
+import (
+	"cmp"
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Equal reports whether a and b are the same JSON value. Objects are equal
+// regardless of member order, with the last of duplicate keys taking effect
+// as in Get, and numbers are compared by their exact decimal value, so 1,
+// 1.0 and 1e0 are all equal. A nil *Value only equals nil.
+func Equal(a, b *Value) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case TypeNumber:
+		return compareNumbers(a, b) == 0
+	case TypeString:
+		return a.STRING() == b.STRING()
+	case TypeArray:
+		x, y := a.ARRAY(), b.ARRAY()
+		if len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !Equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case TypeObject:
+		x, y := lastMembers(a.OBJECT()), lastMembers(b.OBJECT())
+		if len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !Equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+var typeOrder = map[Type]int{
+	TypeNull:   0,
+	TypeFalse:  1,
+	TypeTrue:   2,
+	TypeNumber: 3,
+	TypeString: 4,
+	TypeArray:  5,
+	TypeObject: 6,
+}
+
+// Compare returns -1, 0 or +1 as a sorts before, equal to or after b, in a
+// total order consistent with Equal. Values of different types order as
+// null < false < true < numbers < strings < arrays < objects, with nil first.
+// Strings compare by code point, arrays element by element, and objects as
+// their members sorted by key.
+func Compare(a, b *Value) int {
+	if a == nil || b == nil {
+		switch {
+		case a == b:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	if a.Type != b.Type {
+		return cmp.Compare(typeOrder[a.Type], typeOrder[b.Type])
+	}
+	switch a.Type {
+	case TypeNumber:
+		return compareNumbers(a, b)
+	case TypeString:
+		return strings.Compare(a.STRING(), b.STRING())
+	case TypeArray:
+		return slices.CompareFunc(a.ARRAY(), b.ARRAY(), Compare)
+	case TypeObject:
+		return slices.CompareFunc(sortedMembers(a.OBJECT()), sortedMembers(b.OBJECT()), func(x, y Member) int {
+			if c := strings.Compare(x.K, y.K); c != 0 {
+				return c
+			}
+			return Compare(x.V, y.V)
+		})
+	}
+	return 0
+}
+
+func compareNumbers(a, b *Value) int {
+	an, aok := decimalOf(a)
+	bn, bok := decimalOf(b)
+	if !aok || !bok {
+		return cmp.Compare(a.NUMBER(), b.NUMBER())
+	}
+	if c := cmp.Compare(an.sign(), bn.sign()); c != 0 || an.sign() == 0 {
+		return c
+	}
+
+	// the magnitude is ordered by the position of the leading digit, then
+	// by the digits themselves
+	c := cmp.Compare(len(an.mant)+an.exp, len(bn.mant)+bn.exp)
+	if c == 0 {
+		c = strings.Compare(an.mant, bn.mant)
+	}
+	if an.neg {
+		c = -c
+	}
+	return c
+}
+
+type decimal struct {
+	neg  bool
+	mant string
+	exp  int
+}
+
+func (d decimal) sign() int {
+	switch {
+	case d.mant == "0":
+		return 0
+	case d.neg:
+		return -1
+	}
+	return 1
+}
+
+// decimalOf returns the exact decimal value of a number. It fails for floats
+// that have no JSON form, such as NaN.
+func decimalOf(v *Value) (decimal, bool) {
+	n, err := v.number()
+	if err != nil {
+		return decimal{}, false
+	}
+	neg, mant, exp, ok := n.decimal()
+	return decimal{neg && mant != "0", mant, exp}, ok
+}
+
+func lastMembers(obj Object) map[string]*Value {
+	m := make(map[string]*Value, len(obj))
+	for _, e := range obj {
+		m[e.K] = e.V
+	}
+	return m
+}
+
+func sortedMembers(obj Object) []Member {
+	ms := make([]Member, 0, len(obj))
+	for k, v := range lastMembers(obj) {
+		ms = append(ms, Member{k, v})
+	}
+	slices.SortFunc(ms, func(x, y Member) int {
+		return strings.Compare(x.K, y.K)
+	})
+	return ms
+}
+
+// Hash returns a hash of v that is consistent with Equal: equal values hash
+// the same. It is stable across processes, so it may be stored.
+func Hash(v *Value) uint64 {
+	h := fnv.New64a()
+	writeHash(h, v)
+	return h.Sum64()
+}
+
+func writeHash(h hash.Hash64, v *Value) {
+	var buf []byte
+	if v == nil {
+		h.Write([]byte{0})
+		return
+	}
+	switch v.Type {
+	case TypeNull:
+		buf = append(buf, 'n')
+	case TypeFalse:
+		buf = append(buf, 'f')
+	case TypeTrue:
+		buf = append(buf, 't')
+	case TypeNumber:
+		buf = append(buf, 'd')
+		if d, ok := decimalOf(v); ok {
+			buf = append(buf, byte(d.sign()+1))
+			buf = binary.AppendVarint(buf, int64(d.exp))
+			buf = append(buf, d.mant...)
+		} else {
+			f := v.NUMBER()
+			if math.IsNaN(f) {
+				f = math.NaN()
+			}
+			buf = binary.LittleEndian.AppendUint64(append(buf, 'f'), math.Float64bits(f))
+		}
+	case TypeString:
+		buf = append(buf, 's')
+		buf = binary.AppendUvarint(buf, uint64(len(v.STRING())))
+		buf = append(buf, v.STRING()...)
+	case TypeArray:
+		buf = append(buf, 'a')
+		buf = binary.AppendUvarint(buf, uint64(len(v.ARRAY())))
+		h.Write(buf)
+		for _, e := range v.ARRAY() {
+			writeHash(h, e)
+		}
+		return
+	case TypeObject:
+		// members are combined with a commutative sum so their order does
+		// not matter
+		members := lastMembers(v.OBJECT())
+		sum := uint64(0)
+		for k, e := range members {
+			mh := fnv.New64a()
+			writeHash(mh, &Value{k, TypeString})
+			writeHash(mh, e)
+			sum += mh.Sum64()
+		}
+		buf = append(buf, 'o')
+		buf = binary.AppendUvarint(buf, uint64(len(members)))
+		buf = binary.LittleEndian.AppendUint64(buf, sum)
+	}
+	h.Write(buf)
+}
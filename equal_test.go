@@ -0,0 +1,145 @@
+package lept_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func TestEqual(t *testing.T) {
+	equal := [][2]string{
+		{`null`, `null`},
+		{`true`, `true`},
+		{`1`, `1.0`},
+		{`1`, `1e0`},
+		{`100`, `1E2`},
+		{`0.5`, `5e-1`},
+		{`0`, `-0`},
+		{`0`, `0.0e10`},
+		{`12345678901234567890`, `1.2345678901234567890e19`},
+		{`"aé"`, `"aé"`},
+		{`[1, [2, {}]]`, `[1.0, [2, {}]]`},
+		{`{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`},
+		{`{"a": 1, "a": 2}`, `{"a": 2}`},
+		{`{"a": {"x": [1], "y": null}}`, `{"a": {"y": null, "x": [1]}}`},
+	}
+	for _, tt := range equal {
+		a, b := mustParse(t, tt[0]), mustParse(t, tt[1])
+		if !lept.Equal(a, b) || !lept.Equal(b, a) {
+			t.Errorf("expected %s == %s", tt[0], tt[1])
+		}
+		assertValue(t, lept.Compare(a, b), 0)
+		assertValue(t, lept.Hash(a), lept.Hash(b))
+	}
+
+	different := [][2]string{
+		{`null`, `false`},
+		{`true`, `false`},
+		{`1`, `"1"`},
+		{`1`, `1.0000000000000000001`},
+		{`12345678901234567890`, `12345678901234567891`},
+		{`[1, 2]`, `[2, 1]`},
+		{`[1]`, `[1, 1]`},
+		{`{"a": 1}`, `{"a": 1, "b": 1}`},
+		{`{"a": 1, "a": 2}`, `{"a": 1}`},
+		{`{"a": 1}`, `{"b": 1}`},
+		{`{}`, `[]`},
+		{`""`, `null`},
+	}
+	for _, tt := range different {
+		a, b := mustParse(t, tt[0]), mustParse(t, tt[1])
+		if lept.Equal(a, b) || lept.Equal(b, a) {
+			t.Errorf("expected %s != %s", tt[0], tt[1])
+		}
+		if lept.Compare(a, b) == 0 || lept.Compare(a, b) != -lept.Compare(b, a) {
+			t.Errorf("compare %s and %s: got %d and %d", tt[0], tt[1], lept.Compare(a, b), lept.Compare(b, a))
+		}
+		if lept.Hash(a) == lept.Hash(b) {
+			t.Errorf("hash collision between %s and %s", tt[0], tt[1])
+		}
+	}
+
+	// built values compare with parsed ones
+	assertValue(t, lept.Equal(lept.NewNumber(0.1), mustParse(t, `0.1`)), true)
+	assertValue(t, lept.Equal(lept.NewNumber(3), mustParse(t, `3.00`)), true)
+	assertValue(t, lept.Hash(lept.NewNumber(3)), lept.Hash(mustParse(t, `3.00`)))
+	assertValue(t, lept.Equal(lept.NewNumber(math.NaN()), lept.NewNumber(math.NaN())), true)
+	assertValue(t, lept.Equal(nil, nil), true)
+	assertValue(t, lept.Equal(nil, lept.NewNull()), false)
+}
+
+func TestCompare(t *testing.T) {
+	sorted := []string{
+		`null`,
+		`false`,
+		`true`,
+		`-1e400`,
+		`-10`,
+		`-9.5`,
+		`-0.001`,
+		`0`,
+		`1e-400`,
+		`0.001`,
+		`1`,
+		`1.5`,
+		`9.99`,
+		`10`,
+		`12345678901234567890`,
+		`12345678901234567891`,
+		`1e400`,
+		`""`,
+		`"A"`,
+		`"a"`,
+		`"ab"`,
+		`"é"`,
+		`"😀"`,
+		`[]`,
+		`[1]`,
+		`[1, 1]`,
+		`[2]`,
+		`{}`,
+		`{"a": 1}`,
+		`{"a": 1, "b": 0}`,
+		`{"a": 2}`,
+		`{"b": 0}`,
+	}
+	values := make([]*lept.Value, len(sorted))
+	for i, s := range sorted {
+		values[i] = &lept.Value{U: lept.Number(s), Type: lept.TypeNumber}
+		if v, err := lept.Parse(s); err == nil {
+			values[i] = v
+		}
+	}
+	for i := range values {
+		for j := range values {
+			if got, want := lept.Compare(values[i], values[j]), min(max(i-j, -1), 1); got != want {
+				t.Errorf("compare %s and %s: got %d want %d", sorted[i], sorted[j], got, want)
+			}
+		}
+	}
+
+	shuffled := slices.Clone(values)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	slices.SortFunc(shuffled, lept.Compare)
+	for i := range values {
+		assertValue(t, shuffled[i], values[i])
+	}
+}
+
+func TestHash(t *testing.T) {
+	seen := map[uint64]*lept.Value{}
+	for _, s := range []string{`[1, "a", {"b": [null]}]`, `[1.0, "a", {"b": [null]}]`, `{"x": 1, "y": 2}`, `{"y": 2, "x": 1}`, `{"x": 2, "y": 1}`, `["a", "b"]`, `["ab"]`, `"a"`} {
+		v := mustParse(t, s)
+		h := lept.Hash(v)
+		if prev, ok := seen[h]; ok && !lept.Equal(prev, v) {
+			t.Errorf("hash collision for %s", s)
+		}
+		seen[h] = v
+	}
+	assertValue(t, len(seen), 6)
+}
@@ -0,0 +1,193 @@
+package lept
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type field struct {
+	name  string
+	index []int
+
+	tagged    bool
+	omitEmpty bool
+	asString  bool // the ",string" option, only honored for scalar kinds
+	required  bool
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+func cachedFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.([]field)
+}
+
+func parseTag(tag string) (string, []string) {
+	name, opts, _ := strings.Cut(tag, ",")
+	if opts == "" {
+		return name, nil
+	}
+	return name, strings.Split(opts, ",")
+}
+
+// typeFields returns the fields a struct type is encoded with, promoting the
+// fields of embedded and ",inline" structs. Like encoding/json, a name at a
+// shallower depth hides deeper ones, and of several fields at the same depth
+// a tagged one wins; otherwise all of them are dropped.
+func typeFields(t reflect.Type) []field {
+	type level struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	fields := []field{}
+	hidden := map[string]bool{} // names at shallower depths, kept or not
+	visited := map[reflect.Type]bool{}
+	current := []level{}
+	next := []level{{typ: t}}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		depth := []field{}
+
+		for _, l := range current {
+			if visited[l.typ] {
+				continue
+			}
+			visited[l.typ] = true
+
+			for i := range l.typ.NumField() {
+				sf := l.typ.Field(i)
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if !sf.IsExported() && !(sf.Anonymous && ft.Kind() == reflect.Struct) {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts := parseTag(tag)
+				index := append(slices.Clone(l.index), i)
+
+				inline := slices.Contains(opts, "inline") || sf.Anonymous && name == ""
+				if inline && ft.Kind() == reflect.Struct {
+					next = append(next, level{ft, index})
+					continue
+				}
+				if !sf.IsExported() {
+					continue
+				}
+
+				f := field{
+					name:      name,
+					index:     index,
+					tagged:    name != "",
+					omitEmpty: slices.Contains(opts, "omitempty"),
+					required:  slices.Contains(opts, "required"),
+				}
+				if f.name == "" {
+					f.name = sf.Name
+				}
+				if slices.Contains(opts, "string") {
+					switch ft.Kind() {
+					case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+						reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+						reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+						f.asString = true
+					}
+				}
+				depth = append(depth, f)
+			}
+		}
+
+		for _, f := range depth {
+			if !hidden[f.name] && dominant(depth, f) {
+				fields = append(fields, f)
+			}
+		}
+		for _, f := range depth {
+			hidden[f.name] = true
+		}
+	}
+
+	slices.SortFunc(fields, func(a, b field) int {
+		return slices.Compare(a.index, b.index)
+	})
+	return fields
+}
+
+// dominant reports whether f wins over the other fields of its name at the
+// same depth.
+func dominant(depth []field, f field) bool {
+	tagged := 0
+	total := 0
+	for _, o := range depth {
+		if o.name == f.name {
+			total++
+			if o.tagged {
+				tagged++
+			}
+		}
+	}
+	if total == 1 {
+		return true
+	}
+	return f.tagged && tagged == 1
+}
+
+// lookupField returns the index of the field named name, preferring an exact
+// match over a case-insensitive one, or -1.
+func lookupField(fields []field, name string) int {
+	for i := range fields {
+		if fields[i].name == name {
+			return i
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex that allocates nil embedded
+// pointers when alloc is set, and otherwise reports false on reaching one.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package lept_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+type Base struct {
+	ID      int    `json:"id"`
+	Created string `json:"created,omitempty"`
+	Shadow  string
+}
+
+type Meta struct {
+	Version int
+}
+
+type Document struct {
+	Base
+	*Meta
+	Extra  struct{ Note string } `json:",inline"`
+	Name   string                `json:"name,omitempty"`
+	Count  int                   `json:"count,string"`
+	Ratio  float64               `json:",string"`
+	Flag   bool                  `json:"flag,string,omitempty"`
+	Label  string                `json:"label,string"`
+	Skip   string                `json:"-"`
+	Dash   string                `json:"-,"`
+	Shadow string
+	Title  string
+	hidden string
+}
+
+func TestStructTags(t *testing.T) {
+	data := `{
+		"id": 7,
+		"created": "2024-01-01",
+		"Version": 3,
+		"Note": "inline",
+		"name": "doc",
+		"count": "42",
+		"Ratio": "0.5",
+		"flag": "true",
+		"label": "\"quoted\"",
+		"Skip": "ignored",
+		"-": "dash",
+		"Shadow": "outer",
+		"TITLE": "case insensitive",
+		"hidden": "ignored"
+	}`
+
+	want := Document{
+		Base:  Base{ID: 7, Created: "2024-01-01"},
+		Meta:  &Meta{Version: 3},
+		Name:  "doc",
+		Count: 42,
+		Ratio: 0.5,
+		Flag:  true,
+		Label: "quoted",
+		Dash:  "dash",
+		Title: "case insensitive",
+	}
+	want.Extra.Note = "inline"
+	want.Shadow = "outer"
+
+	v, err := lept.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Document{}
+	if err := lept.Unmarshal(v, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+
+	s, err := lept.MarshalString(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, s, `{"id":7,"created":"2024-01-01","Version":3,"Note":"inline","name":"doc","count":"42","Ratio":"0.5","flag":"true","label":"\"quoted\"","-":"dash","Shadow":"outer","Title":"case insensitive"}`)
+
+	s, err = lept.MarshalString(Document{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, s, `{"id":0,"Note":"","count":"0","Ratio":"0","label":"\"\"","-":"","Shadow":"","Title":""}`)
+}
+
+func TestStructTagConflicts(t *testing.T) {
+	type A struct{ Name, Both string }
+	type B struct {
+		Name string
+		Both string `json:"Both"`
+	}
+	type C struct {
+		A
+		B
+	}
+
+	v, err := lept.Parse(`{"Name": "name", "Both": "both"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := C{}
+	if err := lept.Unmarshal(v, &got); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, got.A.Name, "")
+	assertValue(t, got.B.Name, "")
+	assertValue(t, got.A.Both, "")
+	assertValue(t, got.B.Both, "both")
+
+	// conflicting fields are dropped but still hide deeper ones
+	type E struct{ A int }
+	type F struct{ A int }
+	type Deep struct{ A int }
+	type D struct{ Deep }
+	type T struct {
+		E
+		F
+		D
+	}
+	s, err := lept.MarshalString(T{E{1}, F{2}, D{Deep{3}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, s, `{}`)
+}
+
+func TestStructTagStringErrors(t *testing.T) {
+	type T struct {
+		N int `json:"n,string"`
+	}
+	for _, json := range []string{`{"n": 1}`, `{"n": "x"}`, `{"n": "[1]"}`, `{"n": "\"1\""}`} {
+		v, err := lept.Parse(json)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := lept.Unmarshal(v, &T{}); err == nil {
+			t.Errorf("unmarshal %s: expected error", json)
+		}
+	}
+}
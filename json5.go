@@ -0,0 +1,189 @@
+package lept
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// skipComment skips a JSON5 comment at the current position. An unterminated
+// block comment is left in place for the caller to reject.
+func (c *Context) skipComment() bool {
+	rest := c.json[c.pos:]
+	switch {
+	case strings.HasPrefix(rest, "//"):
+		end := strings.IndexAny(rest, "\n\r\u2028\u2029")
+		if end < 0 {
+			end = len(rest)
+		}
+		c.pos += end
+	case strings.HasPrefix(rest, "/*"):
+		end := strings.Index(rest[2:], "*/")
+		if end < 0 {
+			return false
+		}
+		c.pos += end + 4
+	default:
+		return false
+	}
+	return true
+}
+
+// parseEscape5 decodes the escapes JSON5 adds to JSON, r being the character
+// after the backslash. Any character without a special meaning stands for
+// itself, and an escaped line terminator continues the string.
+func (c *Context) parseEscape5(sb *strings.Builder, r rune) error {
+	switch {
+	case r == 'v':
+		sb.WriteByte('\v')
+	case r == '0' && !unicode.IsDigit(c.peek()):
+		sb.WriteByte(0)
+	case r == 'x':
+		u, err := c.parseHex(2)
+		if err != nil {
+			return err
+		}
+		sb.WriteRune(u)
+	case r >= '0' && r <= '9', r == EOF:
+		c.backup()
+		return ErrInvalidStringEscape
+	case r == '\r':
+		if c.peek() == '\n' {
+			c.next()
+		}
+	case r == '\n', r == '\u2028', r == '\u2029':
+	default:
+		sb.WriteRune(r)
+	}
+	return nil
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc) ||
+		r == '\u200C' || r == '\u200D'
+}
+
+// parseIdentifier reads an unquoted JSON5 member name, which starts with a
+// character accepted by isIdentifierStart.
+func (c *Context) parseIdentifier() string {
+	start := c.pos
+	c.next()
+	for isIdentifierPart(c.peek()) {
+		c.next()
+	}
+	return c.json[start:c.pos]
+}
+
+// parseNumber5 parses a JSON5 number. Finite values are stored as the
+// equivalent JSON Number literal, so "+.5" becomes 0.5 and "0x1F" becomes
+// 31; Infinity and NaN are stored as float64.
+func (v *Value) parseNumber5(c *Context) error {
+	start := c.pos
+	neg := false
+	switch c.peek() {
+	case '-':
+		neg = true
+		c.next()
+	case '+':
+		c.next()
+	}
+
+	sb := strings.Builder{}
+	if neg {
+		sb.WriteByte('-')
+	}
+	rest := c.json[c.pos:]
+	switch {
+	case strings.HasPrefix(rest, "Infinity"), strings.HasPrefix(rest, "NaN"):
+		f := math.NaN()
+		if rest[0] == 'I' {
+			c.pos += len("Infinity")
+			f = math.Inf(1)
+			if neg {
+				f = math.Inf(-1)
+			}
+		} else {
+			c.pos += len("NaN")
+		}
+		if !c.atNumberEnd() {
+			return ErrInvalidValue
+		}
+		v.U = f
+		v.Type = TypeNumber
+		return nil
+	case strings.HasPrefix(rest, "0x"), strings.HasPrefix(rest, "0X"):
+		c.pos += 2
+		digits := c.pos
+		for !c.isAtEnd() && strings.IndexByte("0123456789abcdefABCDEF", c.json[c.pos]) >= 0 {
+			c.pos++
+		}
+		if err := c.limitDigits(start, c.pos-digits); err != nil {
+			return err
+		}
+		i, ok := new(big.Int).SetString(c.json[digits:c.pos], 16)
+		if !ok {
+			return ErrInvalidValue
+		}
+		sb.WriteString(i.String())
+	default:
+		intPart := c.scanDigits()
+		if len(intPart) > 1 && intPart[0] == '0' {
+			return ErrInvalidValue
+		}
+		frac := ""
+		if c.peek() == '.' {
+			c.next()
+			frac = c.scanDigits()
+		}
+		if intPart == "" && frac == "" {
+			return ErrInvalidValue
+		}
+		if intPart == "" {
+			intPart = "0"
+		}
+		sb.WriteString(intPart)
+		if frac != "" {
+			sb.WriteByte('.')
+			sb.WriteString(frac)
+		}
+		if c.peek() == 'e' || c.peek() == 'E' {
+			exp := c.pos
+			c.next()
+			if c.peek() == '+' || c.peek() == '-' {
+				c.next()
+			}
+			if c.scanDigits() == "" {
+				return ErrInvalidValue
+			}
+			sb.WriteString(c.json[exp:c.pos])
+		}
+	}
+
+	if err := c.limitDigits(start, countDigits(c.json[start:c.pos])); err != nil {
+		return err
+	}
+	n := Number(sb.String())
+	if _, err := n.Float64(); err != nil {
+		c.pos = start
+		return ErrOutOfRange
+	}
+	if !c.atNumberEnd() {
+		return ErrInvalidValue
+	}
+	v.U = n
+	v.Type = TypeNumber
+	return nil
+}
+
+func (c *Context) scanDigits() string {
+	start := c.pos
+	for !c.isAtEnd() && isDigit(c.json[c.pos]) {
+		c.pos++
+	}
+	return c.json[start:c.pos]
+}
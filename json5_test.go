@@ -0,0 +1,127 @@
+package lept_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func parseJSON5(t *testing.T, json string) *lept.Value {
+	t.Helper()
+	v, err := lept.ParseOptions{JSON5: true}.Parse(json)
+	if err != nil {
+		t.Fatalf("parse %s failed: %v", json, err)
+	}
+	return v
+}
+
+func TestJSON5(t *testing.T) {
+	v := parseJSON5(t, `// comments
+{
+  unquoted: 'and you can quote me on that',
+  singleQuotes: 'I can use "double quotes" here',
+  lineBreaks: "Look, Mom! \
+No \\n's!",
+  hexadecimal: 0xdecaf,
+  leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+  positiveSign: +1,
+  trailingComma: 'in objects', andIn: ['arrays',],
+  "backwardsCompatible": "with JSON",
+}
+`)
+	got, err := lept.Stringify(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, got, `{"unquoted":"and you can quote me on that","singleQuotes":"I can use \"double quotes\" here","lineBreaks":"Look, Mom! No \\n's!","hexadecimal":912559,"leadingDecimalPoint":0.8675309,"andTrailing":8675309,"positiveSign":1,"trailingComma":"in objects","andIn":["arrays"],"backwardsCompatible":"with JSON"}`)
+}
+
+func TestJSON5Values(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		{`/* block */ 1 // line`, `1`},
+		{"[1, /* a\n b */ 2,\n// c\n]", `[1,2]`},
+		{`[1/**/,2//x` + "\n]", `[1,2]`},
+		{"\ufeff {}", `{}`},
+		{`{a: 1, $b: 2, _c3: 3, ünïcödé: 4, á: 5}`, `{"a":1,"$b":2,"_c3":3,"ünïcödé":4,"á":5}`},
+		{`{'a': 'b', "c": 'd\'e'}`, `{"a":"b","c":"d'e"}`},
+		{`'\v\0\x41\q\'\"'`, `"\u000b\u0000Aq'\""`},
+		{"'a\\\r\nb\\ c'", `"abc"`},
+		{`[0x1F, -0XfF, 0x123456789ABCDEF0123]`, `[31,-255,5373003642731685151011]`},
+		{`[.5, 5., -.5e1, +5.E-1, +0, -0.0]`, `[0.5,5,-0.5e1,5E-1,0,-0.0]`},
+		{`[1e3, 12, 0.25]`, `[1e3,12,0.25]`},
+		{"'a\tb\x01'", `"a\tb\u0001"`},
+	}
+	for _, tt := range tests {
+		got, err := lept.Stringify(parseJSON5(t, tt.json))
+		if err != nil {
+			t.Errorf("stringify %s failed: %v", tt.json, err)
+			continue
+		}
+		assertValue(t, got, tt.want)
+	}
+
+	v := parseJSON5(t, `[Infinity, -Infinity, +Infinity, NaN, -NaN]`)
+	arr := v.ARRAY()
+	assertValue(t, arr[0].NUMBER(), math.Inf(1))
+	assertValue(t, arr[1].NUMBER(), math.Inf(-1))
+	assertValue(t, arr[2].NUMBER(), math.Inf(1))
+	assertValue(t, math.IsNaN(arr[3].NUMBER()), true)
+	assertValue(t, math.IsNaN(arr[4].NUMBER()), true)
+	if _, err := lept.Stringify(v); !errors.Is(err, lept.ErrUnsupportedValue) {
+		t.Errorf("got error %v want %v", err, lept.ErrUnsupportedValue)
+	}
+
+	n, err := parseJSON5(t, `0x7FFFFFFFFFFFFFFF`).Int64()
+	assertValue(t, err, nil)
+	assertValue(t, n, int64(math.MaxInt64))
+}
+
+func TestInvalidJSON5(t *testing.T) {
+	tests := []struct {
+		want error
+		json string
+	}{
+		{lept.ErrUnexpectedChar, `/* unterminated`},
+		{lept.ErrMissComma, `[1 /* unterminated`},
+		{lept.ErrUnexpectedChar, `[,]`},
+		{lept.ErrMissKey, `{,}`},
+		{lept.ErrUnexpectedChar, `[1,,]`},
+		{lept.ErrMissKey, `{1: 2}`},
+		{lept.ErrMissColon, `{a-b: 2}`},
+		{lept.ErrInvalidValue, `01`},
+		{lept.ErrInvalidValue, `0x`},
+		{lept.ErrInvalidValue, `.`},
+		{lept.ErrInvalidValue, `+`},
+		{lept.ErrInvalidValue, `1e`},
+		{lept.ErrInvalidValue, `Infinityx`},
+		{lept.ErrInvalidValue, `Inf`},
+		{lept.ErrOutOfRange, `1e999`},
+		{lept.ErrInvalidStringEscape, `'\1'`},
+		{lept.ErrInvalidStringEscape, `'\01'`},
+		{lept.ErrInvalidUnicodeHex, `'\x4'`},
+		{lept.ErrInvalidStringChar, "'a\nb'"},
+		{lept.ErrInvalidStringChar, "'a\rb'"},
+		{lept.ErrInvalidStringChar, "'a\u2028b'"},
+		{lept.ErrInvalidStringChar, "\"a\u2029b\""},
+		{lept.ErrMissQuotation, `'abc`},
+		{lept.ErrMissQuotation, `'abc"`},
+	}
+	for _, tt := range tests {
+		_, err := lept.ParseOptions{JSON5: true}.Parse(tt.json)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("parse %q: got error %v want %v", tt.json, err, tt.want)
+		}
+	}
+
+	// none of the extensions are accepted by default
+	for _, json := range []string{`// c` + "\n1", `[1,]`, `{a: 1}`, `'a'`, `0x1`, `.5`, `+1`, `Infinity`, `NaN`, `"\v"`} {
+		if _, err := lept.Parse(json); err == nil {
+			t.Errorf("parse %q: expected error", json)
+		}
+	}
+}
@@ -0,0 +1,993 @@
+package lept
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrPathSyntax = errors.New("invalid json path")
+
+// maxPathInt bounds the integers of index and slice selectors to the exact
+// integer range of I-JSON.
+const maxPathInt = 1<<53 - 1
+
+// Path is a compiled RFC 9535 JSONPath query. A Path is safe for concurrent
+// use.
+type Path struct {
+	src string
+	q   *query
+}
+
+func CompilePath(path string) (*Path, error) {
+	p := &pathParser{c: newContext(path)}
+	if !strings.HasPrefix(path, "$") {
+		return nil, p.errorf("query must start with '$'")
+	}
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	if !p.c.isAtEnd() {
+		return nil, p.errorf("unexpected %q", p.c.peek())
+	}
+	return &Path{path, q}, nil
+}
+
+func MustCompilePath(path string) *Path {
+	p, err := CompilePath(path)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Path) String() string {
+	return p.src
+}
+
+// Query returns the nodes selected by p from v, in document order.
+func (p *Path) Query(v *Value) []*Value {
+	return p.q.nodes(v, v)
+}
+
+func Query(v *Value, path string) ([]*Value, error) {
+	p, err := CompilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Query(v), nil
+}
+
+type query struct {
+	relative bool // rooted at the current node "@" instead of "$"
+	segs     []segment
+}
+
+type segment struct {
+	descendant bool
+	sels       []selector
+}
+
+type selector interface {
+	selectFrom(root, v *Value, out []*Value) []*Value
+}
+
+func (q *query) nodes(root, cur *Value) []*Value {
+	nodes := []*Value{root}
+	if q.relative {
+		nodes[0] = cur
+	}
+	for _, seg := range q.segs {
+		var next []*Value
+		for _, v := range nodes {
+			next = seg.apply(root, v, next)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// singular reports whether q selects at most one node.
+func (q *query) singular() bool {
+	for _, seg := range q.segs {
+		if seg.descendant || len(seg.sels) != 1 {
+			return false
+		}
+		switch seg.sels[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (s *segment) apply(root, v *Value, out []*Value) []*Value {
+	for _, sel := range s.sels {
+		out = sel.selectFrom(root, v, out)
+	}
+	if s.descendant {
+		switch v.Type {
+		case TypeArray:
+			for _, e := range v.ARRAY() {
+				out = s.apply(root, e, out)
+			}
+		case TypeObject:
+			for _, m := range v.OBJECT() {
+				out = s.apply(root, m.V, out)
+			}
+		}
+	}
+	return out
+}
+
+type nameSelector string
+
+func (sel nameSelector) selectFrom(root, v *Value, out []*Value) []*Value {
+	if e := v.Get(string(sel)); e != nil {
+		out = append(out, e)
+	}
+	return out
+}
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) selectFrom(root, v *Value, out []*Value) []*Value {
+	switch v.Type {
+	case TypeArray:
+		out = append(out, v.ARRAY()...)
+	case TypeObject:
+		for _, m := range v.OBJECT() {
+			out = append(out, m.V)
+		}
+	}
+	return out
+}
+
+type indexSelector int
+
+func (sel indexSelector) selectFrom(root, v *Value, out []*Value) []*Value {
+	if v.Type != TypeArray {
+		return out
+	}
+	arr := v.ARRAY()
+	i := int(sel)
+	if i < 0 {
+		i += len(arr)
+	}
+	if i >= 0 && i < len(arr) {
+		out = append(out, arr[i])
+	}
+	return out
+}
+
+type sliceSelector struct {
+	start, end, step *int
+}
+
+func (sel sliceSelector) selectFrom(root, v *Value, out []*Value) []*Value {
+	if v.Type != TypeArray {
+		return out
+	}
+	arr := v.ARRAY()
+	n := len(arr)
+	step := 1
+	if sel.step != nil {
+		step = *sel.step
+	}
+	if step == 0 {
+		return out
+	}
+
+	bound := func(i *int, def int) int {
+		if i == nil {
+			return def
+		}
+		if *i < 0 {
+			return *i + n
+		}
+		return *i
+	}
+	if step > 0 {
+		lower := min(max(bound(sel.start, 0), 0), n)
+		upper := min(max(bound(sel.end, n), 0), n)
+		for i := lower; i < upper; i += step {
+			out = append(out, arr[i])
+		}
+	} else {
+		upper := min(max(bound(sel.start, n-1), -1), n-1)
+		lower := min(max(bound(sel.end, -n-1), -1), n-1)
+		for i := upper; lower < i; i += step {
+			out = append(out, arr[i])
+		}
+	}
+	return out
+}
+
+type filterSelector struct {
+	expr logicalExpr
+}
+
+func (sel filterSelector) selectFrom(root, v *Value, out []*Value) []*Value {
+	switch v.Type {
+	case TypeArray:
+		for _, e := range v.ARRAY() {
+			if sel.expr.test(root, e) {
+				out = append(out, e)
+			}
+		}
+	case TypeObject:
+		for _, m := range v.OBJECT() {
+			if sel.expr.test(root, m.V) {
+				out = append(out, m.V)
+			}
+		}
+	}
+	return out
+}
+
+// logicalExpr and valueExpr are the LogicalType and ValueType expressions of
+// a filter. A nil *Value is the special result Nothing.
+type logicalExpr interface {
+	test(root, cur *Value) bool
+}
+
+type valueExpr interface {
+	value(root, cur *Value) *Value
+}
+
+type orExpr []logicalExpr
+
+func (e orExpr) test(root, cur *Value) bool {
+	for _, x := range e {
+		if x.test(root, cur) {
+			return true
+		}
+	}
+	return false
+}
+
+type andExpr []logicalExpr
+
+func (e andExpr) test(root, cur *Value) bool {
+	for _, x := range e {
+		if !x.test(root, cur) {
+			return false
+		}
+	}
+	return true
+}
+
+type notExpr struct {
+	x logicalExpr
+}
+
+func (e notExpr) test(root, cur *Value) bool {
+	return !e.x.test(root, cur)
+}
+
+type existExpr struct {
+	q *query
+}
+
+func (e existExpr) test(root, cur *Value) bool {
+	return len(e.q.nodes(root, cur)) > 0
+}
+
+type comparison struct {
+	op   string
+	l, r valueExpr
+}
+
+func (e comparison) test(root, cur *Value) bool {
+	l, r := e.l.value(root, cur), e.r.value(root, cur)
+	switch e.op {
+	case "==":
+		return Equal(l, r)
+	case "!=":
+		return !Equal(l, r)
+	case "<":
+		return less(l, r)
+	case "<=":
+		return less(l, r) || Equal(l, r)
+	case ">":
+		return less(r, l)
+	default:
+		return less(r, l) || Equal(l, r)
+	}
+}
+
+type literal struct {
+	v *Value
+}
+
+func (e literal) value(root, cur *Value) *Value {
+	return e.v
+}
+
+type singularQuery struct {
+	q *query
+}
+
+func (e singularQuery) value(root, cur *Value) *Value {
+	if nodes := e.q.nodes(root, cur); len(nodes) == 1 {
+		return nodes[0]
+	}
+	return nil
+}
+
+type pathType int
+
+const (
+	valueType pathType = iota
+	logicalType
+	nodesType
+)
+
+func (t pathType) String() string {
+	return [...]string{"ValueType", "LogicalType", "NodesType"}[t]
+}
+
+type pathFunction struct {
+	params []pathType
+	result pathType
+	call   func(args []any) any
+}
+
+// pathFunctions are the function extensions of RFC 9535. Arguments and
+// results are a *Value for ValueType, a bool for LogicalType and a []*Value
+// for NodesType.
+var pathFunctions = map[string]pathFunction{
+	"length": {[]pathType{valueType}, valueType, pathLength},
+	"count":  {[]pathType{nodesType}, valueType, pathCount},
+	"match":  {[]pathType{valueType, valueType}, logicalType, pathMatch},
+	"search": {[]pathType{valueType, valueType}, logicalType, pathSearch},
+	"value":  {[]pathType{nodesType}, valueType, pathValue},
+}
+
+type funcCall struct {
+	name string
+	fn   pathFunction
+	args []any // valueExpr, logicalExpr or *query, following fn.params
+}
+
+// compilePattern compiles a literal pattern of match() or search() once, so
+// that only patterns taken from a query are compiled for each node. It is
+// stored in place of the literal.
+func (f *funcCall) compilePattern() {
+	if f.name != "match" && f.name != "search" {
+		return
+	}
+	l, ok := f.args[1].(literal)
+	if !ok || l.v.Type != TypeString {
+		return
+	}
+	if re, err := compileIRegexp(l.v.STRING(), f.name == "match"); err == nil {
+		f.args[1] = re
+	}
+}
+
+func (f *funcCall) call(root, cur *Value) any {
+	args := make([]any, len(f.args))
+	for i, a := range f.args {
+		switch a := a.(type) {
+		case valueExpr:
+			args[i] = a.value(root, cur)
+		case logicalExpr:
+			args[i] = a.test(root, cur)
+		case *query:
+			args[i] = a.nodes(root, cur)
+		case *regexp.Regexp:
+			args[i] = a
+		}
+	}
+	return f.fn.call(args)
+}
+
+type funcValue struct {
+	f *funcCall
+}
+
+func (e funcValue) value(root, cur *Value) *Value {
+	return e.f.call(root, cur).(*Value)
+}
+
+type funcTest struct {
+	f *funcCall
+}
+
+func (e funcTest) test(root, cur *Value) bool {
+	switch r := e.f.call(root, cur).(type) {
+	case bool:
+		return r
+	case []*Value:
+		return len(r) > 0
+	}
+	return false
+}
+
+func pathLength(args []any) any {
+	v := args[0].(*Value)
+	if v == nil {
+		return v
+	}
+	switch v.Type {
+	case TypeString:
+		return NewNumber(float64(utf8.RuneCountInString(v.STRING())))
+	case TypeArray:
+		return NewNumber(float64(len(v.ARRAY())))
+	case TypeObject:
+		return NewNumber(float64(len(v.OBJECT())))
+	}
+	return (*Value)(nil)
+}
+
+func pathCount(args []any) any {
+	return NewNumber(float64(len(args[0].([]*Value))))
+}
+
+func pathValue(args []any) any {
+	if nodes := args[0].([]*Value); len(nodes) == 1 {
+		return nodes[0]
+	}
+	return (*Value)(nil)
+}
+
+func pathMatch(args []any) any {
+	return regexpTest(args, true)
+}
+
+func pathSearch(args []any) any {
+	return regexpTest(args, false)
+}
+
+// regexpTest matches args[0] against the pattern args[1], which is compiled
+// already when it was a literal.
+func regexpTest(args []any, full bool) bool {
+	s := args[0].(*Value)
+	if s == nil || s.Type != TypeString {
+		return false
+	}
+	re, ok := args[1].(*regexp.Regexp)
+	if !ok {
+		pattern := args[1].(*Value)
+		if pattern == nil || pattern.Type != TypeString {
+			return false
+		}
+		var err error
+		if re, err = compileIRegexp(pattern.STRING(), full); err != nil {
+			return false
+		}
+	}
+	return re.MatchString(s.STRING())
+}
+
+// compileIRegexp compiles an RFC 9485 I-Regexp, where '.' matches any
+// character except line breaks.
+func compileIRegexp(pattern string, full bool) (*regexp.Regexp, error) {
+	sb := strings.Builder{}
+	if full {
+		sb.WriteString(`^(?:`)
+	}
+	escaped, inClass := false, false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '[':
+			inClass = true
+		case r == ']':
+			inClass = false
+		case r == '.' && !inClass:
+			sb.WriteString(`[^\n\r]`)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	if full {
+		sb.WriteString(`)$`)
+	}
+	return regexp.Compile(sb.String())
+}
+
+func less(a, b *Value) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a.Type != b.Type || a.Type != TypeNumber && a.Type != TypeString {
+		return false
+	}
+	return Compare(a, b) < 0
+}
+
+type pathParser struct {
+	c *Context
+}
+
+func (p *pathParser) errorf(format string, args ...any) error {
+	return p.errorAt(p.c.pos, format, args...)
+}
+
+func (p *pathParser) errorAt(pos int, format string, args ...any) error {
+	return newSyntaxError(p.c.json, pos, errorf("%w: "+format, append([]any{ErrPathSyntax}, args...)...))
+}
+
+func (p *pathParser) skipBlank() {
+	for !p.c.isAtEnd() && strings.IndexByte(" \t\n\r", p.c.json[p.c.pos]) >= 0 {
+		p.c.pos++
+	}
+}
+
+// accept consumes s after optional blanks, or leaves the input untouched.
+func (p *pathParser) accept(s string) bool {
+	pos := p.c.pos
+	p.skipBlank()
+	if strings.HasPrefix(p.c.json[p.c.pos:], s) {
+		p.c.pos += len(s)
+		return true
+	}
+	p.c.pos = pos
+	return false
+}
+
+func (p *pathParser) parseQuery() (*query, error) {
+	q := &query{relative: p.c.next() == '@'}
+	for {
+		pos := p.c.pos
+		p.skipBlank()
+		seg := segment{}
+		var err error
+		switch p.c.peek() {
+		case '.':
+			p.c.next()
+			seg, err = p.parseDotSegment()
+		case '[':
+			p.c.next()
+			seg.sels, err = p.parseSelectors()
+		default:
+			p.c.pos = pos
+			return q, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		q.segs = append(q.segs, seg)
+	}
+}
+
+func (p *pathParser) parseDotSegment() (segment, error) {
+	seg := segment{}
+	if p.c.peek() == '.' {
+		p.c.next()
+		seg.descendant = true
+		if p.c.peek() == '[' {
+			p.c.next()
+			sels, err := p.parseSelectors()
+			seg.sels = sels
+			return seg, err
+		}
+	}
+
+	if p.c.peek() == '*' {
+		p.c.next()
+		seg.sels = []selector{wildcardSelector{}}
+		return seg, nil
+	}
+	name := p.parseName()
+	if name == "" {
+		return seg, p.errorf("expected member name")
+	}
+	seg.sels = []selector{nameSelector(name)}
+	return seg, nil
+}
+
+func (p *pathParser) parseName() string {
+	start := p.c.pos
+	for {
+		r := p.c.next()
+		if r == EOF || !isNameChar(r, p.c.pos-p.c.width == start) {
+			p.c.backup()
+			return p.c.json[start:p.c.pos]
+		}
+	}
+}
+
+func isNameChar(r rune, first bool) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r >= 0x80:
+		return true
+	case r >= '0' && r <= '9':
+		return !first
+	}
+	return false
+}
+
+func (p *pathParser) parseSelectors() ([]selector, error) {
+	var sels []selector
+	for {
+		p.skipBlank()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+
+		p.skipBlank()
+		switch p.c.peek() {
+		case ',':
+			p.c.next()
+		case ']':
+			p.c.next()
+			return sels, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *pathParser) parseSelector() (selector, error) {
+	switch r := p.c.peek(); {
+	case r == '\'' || r == '"':
+		s, err := p.parseString()
+		return nameSelector(s), err
+	case r == '*':
+		p.c.next()
+		return wildcardSelector{}, nil
+	case r == '?':
+		p.c.next()
+		p.skipBlank()
+		e, err := p.parseLogical()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{e}, nil
+	case r == '-' || r == ':' || r >= '0' && r <= '9':
+		return p.parseIndexOrSlice()
+	default:
+		return nil, p.errorf("expected selector")
+	}
+}
+
+func (p *pathParser) parseIndexOrSlice() (selector, error) {
+	start, err := p.parseInt()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(":") {
+		if start == nil {
+			return nil, p.errorf("expected index")
+		}
+		return indexSelector(*start), nil
+	}
+
+	sel := sliceSelector{start: start}
+	p.skipBlank()
+	if sel.end, err = p.parseInt(); err != nil {
+		return nil, err
+	}
+	if p.accept(":") {
+		p.skipBlank()
+		if sel.step, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+	}
+	return sel, nil
+}
+
+// parseInt parses an optional integer, returning nil when there is none.
+func (p *pathParser) parseInt() (*int, error) {
+	start := p.c.pos
+	s := p.c.json[start:]
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	j := i
+	for j < len(s) && isDigit(s[j]) {
+		j++
+	}
+	switch {
+	case j == 0:
+		return nil, nil
+	case j == i:
+		return nil, p.errorf("expected digit")
+	case s[i] == '0' && (j > i+1 || i > 0):
+		return nil, p.errorf("invalid integer %q", s[:j])
+	}
+
+	n, err := Number(s[:j]).Int64()
+	if err != nil || n < -maxPathInt || n > maxPathInt {
+		return nil, p.errorf("integer %s out of range", s[:j])
+	}
+	p.c.pos += j
+	v := int(n)
+	return &v, nil
+}
+
+func (p *pathParser) parseString() (string, error) {
+	quote := p.c.next()
+	sb := strings.Builder{}
+	for {
+		switch r := p.c.next(); {
+		case r == EOF:
+			return "", p.errorf("unterminated string")
+		case r == quote:
+			return sb.String(), nil
+		case r == '\\':
+			switch p.c.peek() {
+			case quote:
+				sb.WriteRune(p.c.next())
+			case '\'', '"':
+				return "", p.errorf("%w", ErrInvalidStringEscape)
+			default:
+				if err := p.c.parseEscape(&sb); err != nil {
+					return "", p.errorf("%w", err)
+				}
+			}
+		case r < 0x20:
+			p.c.backup()
+			return "", p.errorf("%w", ErrInvalidStringChar)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// The filter grammar is parsed into loosely typed expressions first, since
+// whether a query or function is a test, a value or a node list depends on
+// where it appears. asLogical and asValue check and convert them.
+
+func (p *pathParser) parseLogical() (logicalExpr, error) {
+	pos := p.c.pos
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	return p.asLogical(e, pos)
+}
+
+func (p *pathParser) parseOr() (any, error) {
+	return p.parseList("||", p.parseAnd, func(es []logicalExpr) logicalExpr { return orExpr(es) })
+}
+
+func (p *pathParser) parseAnd() (any, error) {
+	return p.parseList("&&", p.parseBasic, func(es []logicalExpr) logicalExpr { return andExpr(es) })
+}
+
+func (p *pathParser) parseList(op string, operand func() (any, error), join func([]logicalExpr) logicalExpr) (any, error) {
+	pos := p.c.pos
+	e, err := operand()
+	if err != nil || !p.accept(op) {
+		return e, err
+	}
+
+	first, err := p.asLogical(e, pos)
+	if err != nil {
+		return nil, err
+	}
+	es := []logicalExpr{first}
+	for {
+		p.skipBlank()
+		pos = p.c.pos
+		e, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		x, err := p.asLogical(e, pos)
+		if err != nil {
+			return nil, err
+		}
+		es = append(es, x)
+		if !p.accept(op) {
+			return join(es), nil
+		}
+	}
+}
+
+func (p *pathParser) parseBasic() (any, error) {
+	pos := p.c.pos
+	if p.c.peek() == '!' {
+		p.c.next()
+		p.skipBlank()
+		pos = p.c.pos
+		var e any
+		var err error
+		if p.c.peek() == '(' {
+			e, err = p.parseParen()
+		} else {
+			e, err = p.parsePrimary()
+		}
+		if err != nil {
+			return nil, err
+		}
+		x, err := p.asLogical(e, pos)
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x}, nil
+	}
+	if p.c.peek() == '(' {
+		return p.parseParen()
+	}
+
+	l, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			lv, err := p.asValue(l, pos)
+			if err != nil {
+				return nil, err
+			}
+			p.skipBlank()
+			pos = p.c.pos
+			r, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			rv, err := p.asValue(r, pos)
+			if err != nil {
+				return nil, err
+			}
+			return comparison{op, lv, rv}, nil
+		}
+	}
+	return l, nil
+}
+
+func (p *pathParser) parseParen() (any, error) {
+	p.c.next()
+	p.skipBlank()
+	e, err := p.parseLogical()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(")") {
+		return nil, p.errorf("expected ')'")
+	}
+	return e, nil
+}
+
+// parsePrimary parses a literal, a query or a function call.
+func (p *pathParser) parsePrimary() (any, error) {
+	r := p.c.peek()
+	switch {
+	case r == '@' || r == '$':
+		return p.parseQuery()
+	case r == '\'' || r == '"':
+		s, err := p.parseString()
+		return literal{NewString(s)}, err
+	case r == '-' || r >= '0' && r <= '9':
+		return p.parseNumber()
+	case r >= 'a' && r <= 'z':
+		start := p.c.pos
+		for !p.c.isAtEnd() {
+			b := p.c.json[p.c.pos]
+			if b != '_' && (b < 'a' || b > 'z') && !isDigit(b) {
+				break
+			}
+			p.c.pos++
+		}
+		name := p.c.json[start:p.c.pos]
+		if p.c.peek() == '(' {
+			return p.parseFunction(name, start)
+		}
+		switch name {
+		case "true":
+			return literal{NewBool(true)}, nil
+		case "false":
+			return literal{NewBool(false)}, nil
+		case "null":
+			return literal{NewNull()}, nil
+		}
+		return nil, p.errorAt(start, "unexpected %q", name)
+	default:
+		return nil, p.errorf("expected expression")
+	}
+}
+
+func (p *pathParser) parseNumber() (any, error) {
+	start := p.c.pos
+	for !p.c.isAtEnd() && strings.IndexByte("0123456789+-.eE", p.c.json[p.c.pos]) >= 0 {
+		p.c.pos++
+	}
+	n := Number(p.c.json[start:p.c.pos])
+	if !isNumber(string(n)) {
+		return nil, p.errorAt(start, "invalid number %q", n)
+	}
+	if _, err := n.Float64(); err != nil {
+		return nil, p.errorAt(start, "%w", ErrOutOfRange)
+	}
+	return literal{&Value{n, TypeNumber}}, nil
+}
+
+func (p *pathParser) parseFunction(name string, start int) (any, error) {
+	fn, ok := pathFunctions[name]
+	if !ok {
+		return nil, p.errorAt(start, "unknown function %s()", name)
+	}
+	p.c.next()
+
+	f := &funcCall{name: name, fn: fn}
+	p.skipBlank()
+	for p.c.peek() != ')' {
+		if len(f.args) > 0 {
+			if p.c.peek() != ',' {
+				return nil, p.errorf("expected ',' or ')'")
+			}
+			p.c.next()
+			p.skipBlank()
+		}
+		pos := p.c.pos
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if len(f.args) == len(fn.params) {
+			return nil, p.errorAt(pos, "too many arguments to %s()", name)
+		}
+		var arg any
+		switch fn.params[len(f.args)] {
+		case valueType:
+			arg, err = p.asValue(e, pos)
+		case logicalType:
+			arg, err = p.asLogical(e, pos)
+		case nodesType:
+			if q, ok := e.(*query); ok {
+				arg = q
+			} else {
+				err = p.errorAt(pos, "argument of %s() must be a query", name)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		f.args = append(f.args, arg)
+		p.skipBlank()
+	}
+	p.c.next()
+
+	if len(f.args) != len(fn.params) {
+		return nil, p.errorAt(start, "%s() takes %d arguments", name, len(fn.params))
+	}
+	f.compilePattern()
+	return f, nil
+}
+
+func (p *pathParser) asLogical(e any, pos int) (logicalExpr, error) {
+	switch e := e.(type) {
+	case logicalExpr:
+		return e, nil
+	case *query:
+		return existExpr{e}, nil
+	case *funcCall:
+		if e.fn.result != valueType {
+			return funcTest{e}, nil
+		}
+		return nil, p.errorAt(pos, "result of %s() must be compared", e.name)
+	}
+	return nil, p.errorAt(pos, "literal must be compared")
+}
+
+func (p *pathParser) asValue(e any, pos int) (valueExpr, error) {
+	switch e := e.(type) {
+	case literal:
+		return e, nil
+	case *query:
+		if e.singular() {
+			return singularQuery{e}, nil
+		}
+		return nil, p.errorAt(pos, "query in comparison must be singular")
+	case *funcCall:
+		if e.fn.result == valueType {
+			return funcValue{e}, nil
+		}
+		return nil, p.errorAt(pos, "%s() returns %s, not a comparable value", e.name, e.fn.result)
+	}
+	return nil, p.errorAt(pos, "expected a comparable value")
+}
@@ -0,0 +1,219 @@
+package lept_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+const store = `{ "store": {
+	"book": [
+		{ "category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95 },
+		{ "category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99 },
+		{ "category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99 },
+		{ "category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99 }
+	],
+	"bicycle": { "color": "red", "price": 399 }
+} }`
+
+func testQuery(t *testing.T, json, path, want string) {
+	t.Helper()
+	v, err := lept.Parse(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := lept.Query(v, path)
+	if err != nil {
+		t.Errorf("query %s failed: %v", path, err)
+		return
+	}
+	got, _ := lept.Stringify(&lept.Value{U: lept.Array(nodes), Type: lept.TypeArray})
+	if got != want {
+		t.Errorf("query %s: got %s want %s", path, got, want)
+	}
+}
+
+func TestQueryStore(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$.store.book[*].author", `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{"$..author", `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{"$.store.*", `[[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}],{"color":"red","price":399}]`},
+		{"$.store..price", `[8.95,12.99,8.99,22.99,399]`},
+		{"$..book[2].title", `["Moby Dick"]`},
+		{"$..book[-1].title", `["The Lord of the Rings"]`},
+		{"$..book[0,1].title", `["Sayings of the Century","Sword of Honour"]`},
+		{"$..book[:2].title", `["Sayings of the Century","Sword of Honour"]`},
+		{"$..book[?@.isbn].title", `["Moby Dick","The Lord of the Rings"]`},
+		{"$..book[?@.price<10].title", `["Sayings of the Century","Moby Dick"]`},
+		{`$..book[?@.category == 'fiction' && @.price > 10].author`, `["Evelyn Waugh","J. R. R. Tolkien"]`},
+		{"$..book[?!@.isbn].title", `["Sayings of the Century","Sword of Honour"]`},
+		{"$..book[?@.price > $.store.bicycle.price]", `[]`},
+		{`$["store"]['bicycle'].color`, `["red"]`},
+		{"$.store.bicycle.nothing", `[]`},
+		{"$", `[{"store":{"book":[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}],"bicycle":{"color":"red","price":399}}}]`},
+	}
+	for _, tt := range tests {
+		testQuery(t, store, tt.path, tt.want)
+	}
+}
+
+func TestQuerySelectors(t *testing.T) {
+	arr := `["a", "b", "c", "d", "e", "f", "g"]`
+	testQuery(t, arr, "$[1:3]", `["b","c"]`)
+	testQuery(t, arr, "$[5:]", `["f","g"]`)
+	testQuery(t, arr, "$[1:5:2]", `["b","d"]`)
+	testQuery(t, arr, "$[5:1:-2]", `["f","d"]`)
+	testQuery(t, arr, "$[::-1]", `["g","f","e","d","c","b","a"]`)
+	testQuery(t, arr, "$[-2:]", `["f","g"]`)
+	testQuery(t, arr, "$[-100:100:3]", `["a","d","g"]`)
+	testQuery(t, arr, "$[::0]", `[]`)
+	testQuery(t, arr, "$[ 0 , -1 , 0 ]", `["a","g","a"]`)
+	testQuery(t, arr, "$[7]", `[]`)
+	testQuery(t, arr, "$[-8]", `[]`)
+	testQuery(t, arr, "$.a", `[]`)
+
+	obj := `{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]], "a/b": 7, "é": 8, "dup": 1, "dup": 2}`
+	testQuery(t, obj, "$..j", `[1,4]`)
+	testQuery(t, obj, "$..[0]", `[5,{"j":4}]`)
+	testQuery(t, obj, "$.o[*, 'j']", `[1,2,1]`)
+	testQuery(t, obj, `$['a/b']`, `[7]`)
+	testQuery(t, obj, `$["é"]`, `[8]`)
+	testQuery(t, obj, "$.é", `[8]`)
+	testQuery(t, obj, "$.dup", `[2]`)
+	testQuery(t, obj, `$['\'']`, `[]`)
+	testQuery(t, obj, "$..*", `[{"j":1,"k":2},[5,3,[{"j":4},{"k":6}]],7,8,1,2,1,2,5,3,[{"j":4},{"k":6}],{"j":4},{"k":6},4,6]`)
+}
+
+func TestQueryFilter(t *testing.T) {
+	arr := `[3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}, [1, 2], "abc", null, true]`
+	testQuery(t, arr, "$[?@.b == 'kilo']", `[{"b":"kilo"}]`)
+	testQuery(t, arr, "$[?@ > 3.5]", `[5,4,6]`)
+	testQuery(t, arr, "$[?@ >= 3 && @ <= 5]", `[3,5,4]`)
+	testQuery(t, arr, "$[?@ < 2 || @ == true]", `[1,true]`)
+	testQuery(t, arr, "$[?@.b]", `[{"b":"j"},{"b":"k"},{"b":{}},{"b":"kilo"}]`)
+	testQuery(t, arr, "$[?@.b < 'k']", `[{"b":"j"}]`)
+	testQuery(t, arr, "$[?@.b == $[8].b]", `[{"b":{}}]`)
+	testQuery(t, arr, "$[?@ == null]", `[null]`)
+	testQuery(t, arr, "$[?@ == $[10]]", `[[1,2]]`)
+	testQuery(t, arr, "$[?@ == 1.0]", `[1]`)
+	testQuery(t, arr, "$[?@ == 1e0]", `[1]`)
+	testQuery(t, arr, "$[?@.x == @.y]", `[3,5,1,2,4,6,{"b":"j"},{"b":"k"},{"b":{}},{"b":"kilo"},[1,2],"abc",null,true]`)
+	testQuery(t, arr, "$[?@.x != 1]", `[3,5,1,2,4,6,{"b":"j"},{"b":"k"},{"b":{}},{"b":"kilo"},[1,2],"abc",null,true]`)
+	testQuery(t, arr, "$[?!(@ < 4 || @.b)][?@]", `[1,2]`)
+	testQuery(t, arr, "$[?(@ == 2)]", `[2]`)
+
+	testQuery(t, arr, "$[?length(@) == 2]", `[[1,2]]`)
+	testQuery(t, arr, "$[?length(@.b) == 4]", `[{"b":"kilo"}]`)
+	testQuery(t, arr, "$[?count(@.*) == 1]", `[{"b":"j"},{"b":"k"},{"b":{}},{"b":"kilo"}]`)
+	testQuery(t, arr, "$[?match(@.b, 'k.*')]", `[{"b":"k"},{"b":"kilo"}]`)
+	testQuery(t, arr, "$[?match(@.b, 'k')]", `[{"b":"k"}]`)
+	testQuery(t, arr, "$[?search(@.b, 'l')]", `[{"b":"kilo"}]`)
+	testQuery(t, arr, "$[?search(@, 'a.c')]", `["abc"]`)
+	testQuery(t, arr, "$[?match(@, '[')]", `[]`)
+	testQuery(t, arr, "$[?value(@.*) == 'j']", `[{"b":"j"}]`)
+	testQuery(t, `{"a": "x\ny"}`, "$[?match(@, 'x.y')]", `[]`)
+	testQuery(t, `{"p": "a.", "a": [{"b": "ab", "p": "b"}, {"b": "ba", "p": "b"}]}`, "$.a[?match(@.b, $.p)]", `[{"b":"ab","p":"b"}]`)
+	testQuery(t, `{"a": [{"b": "ab", "p": "b"}, {"b": "ab", "p": "c"}, {"b": "ab", "p": 1}]}`, "$.a[?search(@.b, @.p)]", `[{"b":"ab","p":"b"}]`)
+	testQuery(t, `{"a": [{"b": [1, 2]}, {"b": [3]}]}`, "$.a[?count(@..*) > 2]", `[{"b":[1,2]}]`)
+}
+
+func TestPath(t *testing.T) {
+	p := lept.MustCompilePath("$.store.book[?@.price < 10].title")
+	assertValue(t, p.String(), "$.store.book[?@.price < 10].title")
+	v, err := lept.Parse(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		nodes := p.Query(v)
+		assertValue(t, len(nodes), 2)
+		assertValue(t, nodes[1].STRING(), "Moby Dick")
+	}
+
+	// literal patterns are compiled with the path and reused by each query
+	p = lept.MustCompilePath("$.store.book[?search(@.author, 'Mel.*e')].title")
+	for range 2 {
+		nodes := p.Query(v)
+		assertValue(t, len(nodes), 1)
+		assertValue(t, nodes[0].STRING(), "Moby Dick")
+	}
+
+	// the selected nodes are part of the tree
+	p = lept.MustCompilePath("$.store.book[?@.price < 10].title")
+	p.Query(v)[0].U = "changed"
+	nodes, _ := lept.Query(v, "$.store.book[0].title")
+	assertValue(t, nodes[0].STRING(), "changed")
+}
+
+func TestInvalidPath(t *testing.T) {
+	paths := []string{
+		"",
+		"store",
+		"@.a",
+		"$ ",
+		"$.",
+		"$..",
+		"$.1a",
+		"$.a b",
+		"$[",
+		"$[]",
+		"$[0",
+		"$[01]",
+		"$[-0]",
+		"$[1.0]",
+		"$[9007199254740992]",
+		"$[:-]",
+		"$['a]",
+		`$["\'"]`,
+		`$['\"']`,
+		`$['\x']`,
+		"$['\n']",
+		"$[?]",
+		"$[?@.a ==]",
+		"$[?1]",
+		"$[?'a']",
+		"$[?@.a == @..b]",
+		"$[?@.* == 1]",
+		"$[?@ = 1]",
+		"$[?(@.a]",
+		"$[?@.a === 1]",
+		"$[?length(@)]",
+		"$[?match(@, 'a') == true]",
+		"$[?count(1) > 0]",
+		"$[?length(@.*) > 0]",
+		"$[?length(@, @) > 0]",
+		"$[?length() > 0]",
+		"$[?nope(@)]",
+		"$[?truex]",
+		"$[?@ == 01]",
+		"$[?@ == {}]",
+		"$[?@ == [1]]",
+		"$[?@ == 1e999]",
+	}
+	for _, path := range paths {
+		_, err := lept.CompilePath(path)
+		if !errors.Is(err, lept.ErrPathSyntax) {
+			t.Errorf("path %q: got error %v want %v", path, err, lept.ErrPathSyntax)
+		}
+	}
+
+	_, err := lept.CompilePath("$.a[?@.b == ]")
+	var se *lept.SyntaxError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected *SyntaxError, got %v", err)
+	}
+	assertValue(t, se.Offset, 12)
+	if !strings.Contains(se.Error(), "expected expression") {
+		t.Errorf("unexpected message %q", se.Error())
+	}
+
+	_, err = lept.CompilePath(`$['\u00']`)
+	if !errors.Is(err, lept.ErrInvalidUnicodeHex) {
+		t.Errorf("got error %v want %v", err, lept.ErrInvalidUnicodeHex)
+	}
+}
@@ -7,31 +7,156 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
+	"unsafe"
 )
 
 const (
 	EOF = rune(-1)
 )
 
-var errExpectValue = errors.New("expect value")
-var errInvaildValue = errors.New("invaild value")
-var errPluralRoot = errors.New("plural root")
-var errOutOfRange = errors.New("number out of range")
-var errMissQuotation = errors.New("miss quotation mark")
-var errMissComma = errors.New("miss comma")
-var errMissSquareBracket = errors.New("miss square bracket")
-var errMissCurlyBracket = errors.New("miss curly bracket")
-var errMissKey = errors.New("miss object key")
-var errMissColon = errors.New("miss colon")
-
-var errMismatchType = errors.New("mismatch type")
-var errUnsupportedType = func(v any) error { return errorf("unsupported bencode type %T", v) }
+var ErrExpectValue = errors.New("expect value")
+var ErrInvalidValue = errors.New("invalid value")
+var ErrPluralRoot = errors.New("plural root")
+var ErrOutOfRange = errors.New("number out of range")
+var ErrMissQuotation = errors.New("miss quotation mark")
+var ErrMissComma = errors.New("miss comma")
+var ErrMissSquareBracket = errors.New("miss square bracket")
+var ErrMissCurlyBracket = errors.New("miss curly bracket")
+var ErrMissKey = errors.New("miss object key")
+var ErrMissColon = errors.New("miss colon")
+var ErrInvalidStringEscape = errors.New("invalid string escape")
+var ErrInvalidStringChar = errors.New("invalid string char")
+var ErrInvalidUnicodeHex = errors.New("invalid unicode hex")
+var ErrInvalidUnicodeSurrogate = errors.New("invalid unicode surrogate")
+var ErrUnexpectedChar = errors.New("unexpected character")
+
+var ErrDepthLimit = errors.New("nesting too deep")
+var ErrSizeLimit = errors.New("input too large")
+var ErrStringLimit = errors.New("string too long")
+var ErrArrayLimit = errors.New("array too long")
+var ErrObjectLimit = errors.New("too many object members")
+var ErrNumberLimit = errors.New("number too long")
+
+var ErrMismatchType = errors.New("mismatch type")
+var ErrUnsupportedValue = errors.New("unsupported value")
+var ErrUnsupportedType = errors.New("unsupported type")
+
+func errUnsupportedType(v any) error {
+	if v, ok := v.(*Value); ok {
+		return errorf("%w: json type %q", ErrUnsupportedType, v.Type)
+	}
+	return errorf("%w: Go type %T", ErrUnsupportedType, v)
+}
 
 func errorf(msg string, args ...any) error {
 	return fmt.Errorf(msg, args...)
 }
 
+// UnmarshalTypeError reports a JSON value that cannot be stored in the Go
+// value it was decoded into. It matches ErrMismatchType with errors.Is.
+type UnmarshalTypeError struct {
+	Value Type         // JSON type of the value
+	Type  reflect.Type // Go type it could not be assigned to
+	Field string       // path from the root to the value, e.g. "publisher.Company"
+	Err   error        // the reason, such as ErrOutOfRange, if more specific than the type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	msg := ""
+	if e.Field != "" {
+		msg = fmt.Sprintf("cannot unmarshal %s into Go struct field %s of type %s", e.Value, e.Field, e.Type)
+	} else {
+		msg = fmt.Sprintf("cannot unmarshal %s into Go value of type %s", e.Value, e.Type)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *UnmarshalTypeError) Is(target error) bool {
+	return target == ErrMismatchType
+}
+
+func (e *UnmarshalTypeError) Unwrap() error {
+	return e.Err
+}
+
+// LimitError reports input exceeding one of the limits in ParseOptions. It is
+// returned inside a SyntaxError locating where the limit was crossed.
+type LimitError struct {
+	Err error // which limit, such as ErrDepthLimit
+	Max int   // the configured maximum
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v (max %d)", e.Err, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+func limit(n, max int, err error) error {
+	if max > 0 && n > max {
+		return &LimitError{err, max}
+	}
+	return nil
+}
+
+type SyntaxError struct {
+	Offset  int    // byte offset of the error in the input
+	Line    int    // 1-based line number
+	Column  int    // 1-based column, counted in runes
+	Rune    rune   // the rune at Offset, EOF at the end of input
+	Context string // the input surrounding Offset on the same line
+	Err     error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%v at line %d, column %d: %q", e.Err, e.Line, e.Column, e.Context)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+const syntaxContext = 16
+
+func newSyntaxError(json string, offset int, err error) *SyntaxError {
+	offset = min(max(offset, 0), len(json))
+	lineStart := strings.LastIndexByte(json[:offset], '\n') + 1
+	lineEnd := len(json)
+	if i := strings.IndexByte(json[offset:], '\n'); i >= 0 {
+		lineEnd = offset + i
+	}
+
+	start := max(lineStart, offset-syntaxContext)
+	for start > lineStart && !utf8.RuneStart(json[start]) {
+		start--
+	}
+	end := min(lineEnd, offset+syntaxContext)
+	for end < lineEnd && !utf8.RuneStart(json[end]) {
+		end++
+	}
+
+	r := EOF
+	if offset < len(json) {
+		r, _ = utf8.DecodeRuneInString(json[offset:])
+	}
+
+	return &SyntaxError{
+		Offset:  offset,
+		Line:    strings.Count(json[:offset], "\n") + 1,
+		Column:  utf8.RuneCountInString(json[lineStart:offset]) + 1,
+		Rune:    r,
+		Context: strings.Clone(strings.TrimRight(json[start:end], "\r")), // json may alias a caller's buffer
+		Err:     err,
+	}
+}
+
 type Type string
 
 const (
@@ -105,14 +230,20 @@ type Context struct {
 	json  string
 	pos   int
 	width int
+	opts  ParseOptions
 }
 
 func (c *Context) parseWhitespace() {
-	r := c.next()
-	for unicode.IsSpace(r) {
-		r = c.next()
+	for {
+		r := c.next()
+		for unicode.IsSpace(r) || c.opts.JSON5 && r == '\uFEFF' {
+			r = c.next()
+		}
+		c.backup()
+		if !c.opts.JSON5 || !c.skipComment() {
+			return
+		}
 	}
-	c.backup()
 }
 
 func (c *Context) next() (r rune) {
@@ -140,7 +271,26 @@ func (c *Context) isAtEnd() bool {
 }
 
 func newContext(json string) *Context {
-	return &Context{json, 0, 0}
+	return &Context{json: json}
+}
+
+// checkSize fails for input longer than MaxBytes, locating the error where
+// the limit is crossed.
+func (c *Context) checkSize() error {
+	if err := limit(len(c.json), c.opts.MaxBytes, ErrSizeLimit); err != nil {
+		c.pos = c.opts.MaxBytes
+		return err
+	}
+	return nil
+}
+
+// syntaxError locates err at the current position, which the parse functions
+// leave on the offending input when they fail.
+func (c *Context) syntaxError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return newSyntaxError(c.json, c.pos, err)
 }
 
 type Value struct {
@@ -159,153 +309,307 @@ func (v Value) String() string {
 	}
 }
 
-func (v *Value) parse(json string) error {
-	c := newContext(json)
+func (v *Value) parse(c *Context) error {
+	if err := c.checkSize(); err != nil {
+		return c.syntaxError(err)
+	}
 	c.parseWhitespace()
 	v.Type = TypeNull
 	err := v.parseValue(c)
 	if err == nil {
 		c.parseWhitespace()
 		if !c.isAtEnd() {
-			err = errPluralRoot
+			err = ErrPluralRoot
 		}
 	}
-	return err
+	return c.syntaxError(err)
 }
 
+// parseValue parses a value without recursing into arrays and objects: the
+// containers still open are kept on an explicit stack, so deep nesting costs
+// heap instead of goroutine stack.
 func (v *Value) parseValue(c *Context) error {
-	if !c.isAtEnd() {
-		switch c.peek() {
-		case 'n':
-			return v.parseLiteral(c, "null", TypeNull)
-		case 't':
-			return v.parseLiteral(c, "true", TypeTrue)
-		case 'f':
-			return v.parseLiteral(c, "false", TypeFalse)
-		case '"':
-			return v.parseString(c)
-		case '[':
-			return v.parseArray(c)
-		case '{':
-			return v.parseObject(c)
-		default:
-			if unicode.IsDigit(c.peek()) || c.peek() == '-' {
-				return v.parseNumber(c)
-			} else {
-				return fmt.Errorf("unexpected character %q", c.peek())
-			}
-		}
-	} else {
-		return errExpectValue
-	}
-}
-
-func (v *Value) parseObject(c *Context) error {
-	ms := Object{}
-	c.next()
-	c.parseWhitespace()
-	if c.peek() == '}' {
-		c.next()
-		v.Type = TypeObject
-		v.U = ms
-		return nil
-	}
-
+	var stack []container
+	e := v
 	for {
-		m := Member{}
-
-		if !c.isAtEnd() {
-			if c.peek() != '"' {
-				return errMissKey
-			}
-			s, err := v.parseStringRaw(c)
-			if err != nil {
+		if r := c.peek(); r == '[' || r == '{' {
+			if err := limit(len(stack)+1, c.opts.MaxDepth, ErrDepthLimit); err != nil {
 				return err
 			}
-			m.K = s
-
+			stack = append(stack, newContainer(e, r))
+			f := &stack[len(stack)-1]
+			c.next()
 			c.parseWhitespace()
-			if c.peek() != ':' {
-				return errMissColon
+			if c.peek() != f.closing {
+				var err error
+				if e, err = f.element(c); err != nil {
+					return err
+				}
+				continue
 			}
 			c.next()
+			f.finish()
+			stack = stack[:len(stack)-1]
+		} else if err := e.parseScalar(c); err != nil {
+			return err
+		}
 
-			c.parseWhitespace()
-			e := &Value{}
-			err = e.parseValue(c)
-			if err != nil {
-				return err
-			}
-			m.V = e
-			ms = append(ms, m)
+		// e is complete: add it to the innermost container, then either
+		// start the next element or close the containers that end here
+		for ; len(stack) > 0; stack = stack[:len(stack)-1] {
+			f := &stack[len(stack)-1]
+			f.add(e)
 			c.parseWhitespace()
 			if c.peek() == ',' {
 				c.next()
 				c.parseWhitespace()
-			} else if c.peek() == '}' {
-				c.next()
-				v.Type = TypeObject
-				v.U = ms
-				return nil
-			} else {
-				return errMissComma
+				if !c.opts.JSON5 || c.peek() != f.closing {
+					var err error
+					if e, err = f.element(c); err != nil {
+						return err
+					}
+					break
+				}
 			}
-		} else {
-			return errMissCurlyBracket
+			if c.peek() != f.closing {
+				return ErrMissComma
+			}
+			c.next()
+			f.finish()
+			e = f.v
+		}
+		if len(stack) == 0 {
+			return nil
 		}
 	}
 }
 
-func (v *Value) parseArray(c *Context) error {
-	arr := Array{}
+func (v *Value) parseScalar(c *Context) error {
+	if c.isAtEnd() {
+		return ErrExpectValue
+	}
+	switch c.peek() {
+	case 'n':
+		return v.parseLiteral(c, "null", TypeNull)
+	case 't':
+		return v.parseLiteral(c, "true", TypeTrue)
+	case 'f':
+		return v.parseLiteral(c, "false", TypeFalse)
+	case '"':
+		return v.parseString(c)
+	case '\'':
+		if c.opts.JSON5 {
+			return v.parseString(c)
+		}
+		return ErrUnexpectedChar
+	default:
+		if c.opts.JSON5 && strings.ContainsRune("+-.0123456789IN", c.peek()) {
+			return v.parseNumber5(c)
+		}
+		if unicode.IsDigit(c.peek()) || c.peek() == '-' {
+			return v.parseNumber(c)
+		}
+		return ErrUnexpectedChar
+	}
+}
+
+// container is an array or object being parsed by parseValue.
+type container struct {
+	v       *Value
+	closing rune
+	arr     Array
+	obj     Object
+	key     string // name of the member being parsed
+}
+
+func newContainer(v *Value, open rune) container {
+	if open == '[' {
+		return container{v: v, closing: ']', arr: Array{}}
+	}
+	return container{v: v, closing: '}', obj: Object{}}
+}
+
+// element reads up to the next element's value, which is the member name and
+// colon for objects, and returns the Value to parse it into.
+func (f *container) element(c *Context) (*Value, error) {
+	if f.closing == ']' {
+		if c.isAtEnd() {
+			return nil, ErrMissSquareBracket
+		}
+		if err := limit(len(f.arr)+1, c.opts.MaxArrayLen, ErrArrayLimit); err != nil {
+			return nil, err
+		}
+		return &Value{}, nil
+	}
+
+	if c.isAtEnd() {
+		return nil, ErrMissCurlyBracket
+	}
+	if err := limit(len(f.obj)+1, c.opts.MaxObjectMembers, ErrObjectLimit); err != nil {
+		return nil, err
+	}
+	k, err := f.v.parseKey(c)
+	if err != nil {
+		return nil, err
+	}
+	c.parseWhitespace()
+	if c.peek() != ':' {
+		return nil, ErrMissColon
+	}
 	c.next()
 	c.parseWhitespace()
-	if c.peek() == ']' {
-		c.next()
-		v.Type = TypeArray
-		v.U = arr
-		return nil
+	f.key = k
+	return &Value{}, nil
+}
+
+func (f *container) add(e *Value) {
+	if f.closing == ']' {
+		f.arr = append(f.arr, e)
+	} else {
+		f.obj = append(f.obj, Member{f.key, e})
+	}
+}
+
+func (f *container) finish() {
+	if f.closing == ']' {
+		f.v.Type = TypeArray
+		f.v.U = f.arr
+	} else {
+		f.v.Type = TypeObject
+		f.v.U = f.obj
+	}
+}
+
+func (v *Value) parseKey(c *Context) (string, error) {
+	switch c.peek() {
+	case '"':
+		return v.parseStringRaw(c)
+	case '\'':
+		if c.opts.JSON5 {
+			return v.parseStringRaw(c)
+		}
+	default:
+		if c.opts.JSON5 && isIdentifierStart(c.peek()) {
+			s := c.parseIdentifier()
+			return s, limit(len(s), c.opts.MaxStringLen, ErrStringLimit)
+		}
 	}
+	return "", ErrMissKey
+}
+
+func (v *Value) parseStringRaw(c *Context) (string, error) {
+	quote := c.next()
+	start := c.pos
+	var sb *strings.Builder
 	for {
-		if !c.isAtEnd() {
-			e := &Value{}
-			err := e.parseValue(c)
+		if c.isAtEnd() {
+			return "", ErrMissQuotation
+		}
+		n := c.pos - start
+		if sb != nil {
+			n += sb.Len()
+		}
+		if err := limit(n, c.opts.MaxStringLen, ErrStringLimit); err != nil {
+			return "", err
+		}
+		pos := c.pos
+		r := c.next()
+		switch {
+		case r == quote:
+			if sb == nil {
+				return c.json[start:pos], nil
+			}
+			sb.WriteString(c.json[start:pos])
+			return sb.String(), nil
+		case r == '\\':
+			if sb == nil {
+				sb = &strings.Builder{}
+			}
+			sb.WriteString(c.json[start:pos])
+			if err := c.parseEscape(sb); err != nil {
+				return "", err
+			}
+			start = c.pos
+		case c.opts.JSON5 && (r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029'),
+			!c.opts.JSON5 && r < 0x20:
+			// JSON5 only forbids line terminators
+			c.backup()
+			return "", ErrInvalidStringChar
+		}
+	}
+}
+
+func (c *Context) parseEscape(sb *strings.Builder) error {
+	switch r := c.next(); r {
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case '/':
+		sb.WriteByte('/')
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case 'u':
+		u, err := c.parseHex(4)
+		if err != nil {
+			return err
+		}
+		if utf16.IsSurrogate(u) {
+			if u >= 0xDC00 {
+				return ErrInvalidUnicodeSurrogate
+			}
+			if c.next() != '\\' || c.next() != 'u' {
+				return ErrInvalidUnicodeSurrogate
+			}
+			l, err := c.parseHex(4)
 			if err != nil {
 				return err
 			}
-			arr = append(arr, e)
-			c.parseWhitespace()
-			if c.peek() == ',' {
-				c.next()
-				c.parseWhitespace()
-			} else if c.peek() == ']' {
-				c.next()
-				v.Type = TypeArray
-				v.U = arr
-				return nil
-			} else {
-				return errMissComma
+			u = utf16.DecodeRune(u, l)
+			if u == utf8.RuneError {
+				return ErrInvalidUnicodeSurrogate
 			}
-		} else {
-			return errMissSquareBracket
 		}
+		sb.WriteRune(u)
+	default:
+		if c.opts.JSON5 {
+			return c.parseEscape5(sb, r)
+		}
+		c.backup()
+		return ErrInvalidStringEscape
 	}
+	return nil
 }
 
-func (v *Value) parseStringRaw(c *Context) (string, error) {
-	c.next()
-	start := c.pos
-	for c.peek() != '"' {
-		if !c.isAtEnd() {
-			c.next()
-		} else {
-			return "", errMissQuotation
+func (c *Context) parseHex(n int) (rune, error) {
+	u := rune(0)
+	for range n {
+		if c.isAtEnd() {
+			return 0, ErrInvalidUnicodeHex
 		}
+		b := c.json[c.pos]
+		u <<= 4
+		switch {
+		case b >= '0' && b <= '9':
+			u |= rune(b - '0')
+		case b >= 'a' && b <= 'f':
+			u |= rune(b - 'a' + 10)
+		case b >= 'A' && b <= 'F':
+			u |= rune(b - 'A' + 10)
+		default:
+			return 0, ErrInvalidUnicodeHex
+		}
+		c.pos++
 	}
-	s := c.json[start:c.pos]
-	c.next()
-
-	return s, nil
+	return u, nil
 }
 
 func (v *Value) parseString(c *Context) error {
@@ -327,10 +631,8 @@ func (v *Value) parseNumber(c *Context) error {
 	if c.peek() == '0' {
 		c.next()
 	} else {
-		if c.peek() == '0' {
-			return errInvaildValue
-		} else {
-			c.next()
+		if !unicode.IsDigit(c.peek()) {
+			return ErrInvalidValue
 		}
 		for unicode.IsDigit(c.peek()) {
 			c.next()
@@ -340,7 +642,7 @@ func (v *Value) parseNumber(c *Context) error {
 	if c.peek() == '.' {
 		c.next()
 		if !unicode.IsDigit(c.peek()) {
-			return errInvaildValue
+			return ErrInvalidValue
 		}
 		for unicode.IsDigit(c.peek()) {
 			c.next()
@@ -353,7 +655,7 @@ func (v *Value) parseNumber(c *Context) error {
 			c.next()
 		}
 		if !unicode.IsDigit(c.peek()) {
-			return errInvaildValue
+			return ErrInvalidValue
 		}
 
 		for unicode.IsDigit(c.peek()) {
@@ -361,15 +663,17 @@ func (v *Value) parseNumber(c *Context) error {
 		}
 	}
 
-	n, err := strconv.ParseFloat(c.json[start:c.pos], 64)
-	if err != nil {
-		return errOutOfRange
+	if err := c.limitDigits(start, countDigits(c.json[start:c.pos])); err != nil {
+		return err
+	}
+	n := Number(c.json[start:c.pos])
+	if _, err := n.Float64(); err != nil {
+		c.pos = start
+		return ErrOutOfRange
 	}
 
-	if !c.isAtEnd() {
-		if !unicode.IsSpace(c.peek()) && c.peek() != ',' && c.peek() != ']' {
-			return errInvaildValue
-		}
+	if !c.atNumberEnd() {
+		return ErrInvalidValue
 	}
 
 	v.U = n
@@ -377,9 +681,38 @@ func (v *Value) parseNumber(c *Context) error {
 	return nil
 }
 
+// limitDigits checks the digit count of the number at start against
+// MaxNumberDigits before it is converted.
+func (c *Context) limitDigits(start, digits int) error {
+	if err := limit(digits, c.opts.MaxNumberDigits, ErrNumberLimit); err != nil {
+		c.pos = start
+		return err
+	}
+	return nil
+}
+
+func countDigits(s string) int {
+	n := 0
+	for i := range len(s) {
+		if isDigit(s[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+// atNumberEnd reports whether the character after a number may follow it.
+func (c *Context) atNumberEnd() bool {
+	if c.isAtEnd() {
+		return true
+	}
+	r := c.peek()
+	return unicode.IsSpace(r) || r == ',' || r == ']' || r == '}' || c.opts.JSON5 && r == '/'
+}
+
 func (v *Value) parseLiteral(c *Context, litetal string, typ Type) error {
 	if !strings.HasPrefix(c.json[c.pos:], litetal) {
-		return errInvaildValue
+		return ErrInvalidValue
 	}
 
 	c.pos += len(litetal)
@@ -445,6 +778,30 @@ func (v *Value) Set(key string, val *Value) *Value {
 	return nil
 }
 
+// Clone returns a deep copy of v that shares no arrays or objects with it.
+// A nil v, or a nil element or member inside it, is copied as nil.
+func (v *Value) Clone() *Value {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	switch v.Type {
+	case TypeArray:
+		arr := make(Array, len(v.ARRAY()))
+		for i, e := range v.ARRAY() {
+			arr[i] = e.Clone()
+		}
+		c.U = arr
+	case TypeObject:
+		obj := make(Object, len(v.OBJECT()))
+		for i, m := range v.OBJECT() {
+			obj[i] = Member{m.K, m.V.Clone()}
+		}
+		c.U = obj
+	}
+	return &c
+}
+
 func (v *Value) BOOL() bool {
 	if v.Type == TypeTrue || v.Type == TypeFalse {
 		return v.U.(bool)
@@ -471,7 +828,15 @@ func (v *Value) STRING() string {
 
 func (v *Value) NUMBER() float64 {
 	if v.Type == TypeNumber {
-		return v.U.(float64)
+		if n, ok := v.U.(float64); ok {
+			return n
+		}
+		n, err := v.number()
+		if err != nil {
+			return 0
+		}
+		f, _ := n.Float64()
+		return f
 	} else {
 		return 0
 	}
@@ -521,103 +886,54 @@ func NewNull() *Value {
 	return &Value{"null", TypeNull}
 }
 
-func Parse(data string) (*Value, error) {
+// ParseOptions configures parsing. The zero value parses strict RFC 8259
+// JSON like Parse.
+type ParseOptions struct {
+	// JSON5 accepts the JSON5 extensions: comments, trailing commas,
+	// single-quoted strings, control characters other than line breaks in
+	// strings, identifier keys, hexadecimal numbers, leading and trailing
+	// decimal points, a leading '+', Infinity and NaN.
+	JSON5 bool
+
+	// Limits for untrusted input; zero means unlimited. Input exceeding one
+	// fails with a *LimitError. A Decoder applies them to each value it reads
+	// once given these options with SetParseOptions.
+	MaxDepth         int // nesting of arrays and objects
+	MaxBytes         int // length of the input
+	MaxStringLen     int // bytes of a decoded string or member name
+	MaxArrayLen      int // elements of an array
+	MaxObjectMembers int // members of an object, counting duplicates
+	MaxNumberDigits  int // digits of a number, including fraction and exponent
+
+	// ZeroCopy makes ParseBytes use its input in place instead of copying
+	// it. Strings without escapes, member names without escapes and number
+	// literals in the result then point into the input, which must not be
+	// modified for as long as the result is in use: Go assumes strings are
+	// immutable, so this would corrupt them. It has no effect on Parse,
+	// whose results already share memory with the input string.
+	ZeroCopy bool
+}
+
+func (opts ParseOptions) Parse(data string) (*Value, error) {
 	v := &Value{}
-	return v, v.parse(data)
+	c := newContext(data)
+	c.opts = opts
+	return v, v.parse(c)
 }
 
-func Unmarshal(parsed *Value, v any) error {
-	if reflect.TypeOf(v).Kind() != reflect.Ptr {
-		return errorf("Attempt to unmarshal into a non-pointer")
-	}
-
-	return unmarshalValue(parsed, reflect.Indirect(reflect.ValueOf(v)))
+func Parse(data string) (*Value, error) {
+	return ParseOptions{}.Parse(data)
 }
 
-func unmarshalValue(parsed *Value, v reflect.Value) (err error) {
-	switch parsed.Type {
-	case TypeTrue, TypeFalse:
-		switch v.Kind() {
-		case reflect.Bool:
-			v.SetBool(parsed.BOOL())
-		case reflect.Interface:
-			v.Set(reflect.ValueOf(parsed.BOOL()))
-		default:
-			err = errMismatchType
-		}
-	case TypeNumber:
-		switch v.Kind() {
-		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
-			v.SetInt(int64(parsed.NUMBER()))
-		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
-			v.SetUint(uint64(parsed.NUMBER()))
-		case reflect.Float64, reflect.Float32:
-			v.SetFloat(parsed.NUMBER())
-		case reflect.Interface:
-			v.Set(reflect.ValueOf(parsed.NUMBER()))
-		default:
-			err = errMismatchType
-		}
-	case TypeString:
-		switch v.Kind() {
-		case reflect.String:
-			if !v.CanSet() {
-				x := ""
-				v = reflect.ValueOf(&x).Elem()
-			}
-			v.SetString(parsed.STRING())
-		case reflect.Interface:
-			v.Set(reflect.ValueOf(parsed.STRING()))
-		default:
-			err = errMismatchType
-		}
-	case TypeArray:
-		switch v.Kind() {
-		case reflect.Slice:
-			l := reflect.MakeSlice(v.Type(), len(parsed.ARRAY()), len(parsed.ARRAY()))
-			for i, e := range parsed.ARRAY() {
-				if err = unmarshalValue(e, reflect.Indirect(l.Index(i))); err != nil {
-					return
-				}
-			}
-			v.Set(l)
-		case reflect.Array:
-			if v.Len() != len(parsed.ARRAY()) {
-				err = fmt.Errorf("array length mismatch: %d vs %d", v.Len(), len(parsed.ARRAY()))
-				return
-			}
-			for i, e := range parsed.ARRAY() {
-				elem := v.Index(i)
-				if err = unmarshalValue(e, elem); err != nil {
-					return
-				}
-			}
-		default:
-			err = errMismatchType
-		}
-	case TypeObject:
-		t := v.Type()
-		for i := range v.NumField() {
-			f := v.Field(i)
-			if !f.CanSet() {
-				continue
-			}
-			key := t.Field(i).Tag.Get("json")
-			if key == "" {
-				continue
-			}
-
-			v := parsed.OBJECT().Get(key)
-			if v == nil {
-				continue
-			}
-			if err = unmarshalValue(v, reflect.Indirect(f)); err != nil {
-				return
-			}
-		}
-	case TypeNull:
-	default:
-		err = errUnsupportedType(parsed)
+// ParseBytes parses data like Parse. Unless ZeroCopy is set, data is copied
+// and may be reused as soon as ParseBytes returns.
+func (opts ParseOptions) ParseBytes(data []byte) (*Value, error) {
+	if !opts.ZeroCopy {
+		return opts.Parse(string(data))
 	}
-	return
+	return opts.Parse(unsafe.String(unsafe.SliceData(data), len(data)))
+}
+
+func ParseBytes(data []byte) (*Value, error) {
+	return ParseOptions{}.ParseBytes(data)
 }
@@ -1,10 +1,14 @@
 package lept_test
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"runtime/debug"
 	"strconv"
+	"strings"
 	"testing"
+	"unsafe"
 
 	"github.com/wasuppu/lept"
 )
@@ -72,10 +76,246 @@ func TestBaseType(t *testing.T) {
 	t.Run("string", func(t *testing.T) {
 		testString(t, ``, "\"\"")
 		testString(t, `Hello`, "\"Hello\"")
-		testString(t, `Hello\nWorld`, "\"Hello\\nWorld\"")
+		testString(t, "Hello\nWorld", "\"Hello\\nWorld\"")
+		testString(t, "\" \\ / \b \f \n \r \t", "\"\\\" \\\\ \\/ \\b \\f \\n \\r \\t\"")
+		testString(t, "Hello\x00World", "\"Hello\\u0000World\"")
+		testString(t, "\x24", "\"\\u0024\"")                    /* Dollar sign U+0024 */
+		testString(t, "\xC2\xA2", "\"\\u00A2\"")                /* Cents sign U+00A2 */
+		testString(t, "\xE2\x82\xAC", "\"\\u20AC\"")            /* Euro sign U+20AC */
+		testString(t, "\xF0\x9D\x84\x9E", "\"\\uD834\\uDD1E\"") /* G clef sign U+1D11E */
+		testString(t, "\xF0\x9D\x84\x9E", "\"\\ud834\\udd1e\"") /* G clef sign U+1D11E */
+		testString(t, "日本語", "\"日本語\"")
 	})
 }
 
+func TestInvalidString(t *testing.T) {
+	t.Run("miss quotation mark", func(t *testing.T) {
+		testError(t, lept.ErrMissQuotation, "\"")
+		testError(t, lept.ErrMissQuotation, "\"abc")
+	})
+
+	t.Run("invalid string escape", func(t *testing.T) {
+		testError(t, lept.ErrInvalidStringEscape, "\"\\v\"")
+		testError(t, lept.ErrInvalidStringEscape, "\"\\'\"")
+		testError(t, lept.ErrInvalidStringEscape, "\"\\0\"")
+		testError(t, lept.ErrInvalidStringEscape, "\"\\x12\"")
+	})
+
+	t.Run("invalid string char", func(t *testing.T) {
+		testError(t, lept.ErrInvalidStringChar, "\"\x01\"")
+		testError(t, lept.ErrInvalidStringChar, "\"\x1F\"")
+	})
+
+	t.Run("invalid unicode hex", func(t *testing.T) {
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u0\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u01\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u012\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u/000\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\uG000\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u0/00\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u0G00\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u00/0\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u00G0\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u000/\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u000G\"")
+		testError(t, lept.ErrInvalidUnicodeHex, "\"\\u 123\"")
+	})
+
+	t.Run("invalid unicode surrogate", func(t *testing.T) {
+		testError(t, lept.ErrInvalidUnicodeSurrogate, "\"\\uD800\"")
+		testError(t, lept.ErrInvalidUnicodeSurrogate, "\"\\uDBFF\"")
+		testError(t, lept.ErrInvalidUnicodeSurrogate, "\"\\uD800\\\\\"")
+		testError(t, lept.ErrInvalidUnicodeSurrogate, "\"\\uD800\\uDBFF\"")
+		testError(t, lept.ErrInvalidUnicodeSurrogate, "\"\\uD800\\uE000\"")
+		testError(t, lept.ErrInvalidUnicodeSurrogate, "\"\\uDC00\"")
+	})
+}
+
+func TestInvalidValue(t *testing.T) {
+	testError(t, lept.ErrExpectValue, "")
+	testError(t, lept.ErrExpectValue, " ")
+
+	testError(t, lept.ErrInvalidValue, "nul")
+	testError(t, lept.ErrInvalidValue, "tru")
+	testError(t, lept.ErrInvalidValue, "-")
+	testError(t, lept.ErrInvalidValue, "-a")
+	testError(t, lept.ErrInvalidValue, "1.")
+	testError(t, lept.ErrInvalidValue, "0123")
+	testError(t, lept.ErrInvalidValue, "0x0")
+	testError(t, lept.ErrUnexpectedChar, "+0")
+	testError(t, lept.ErrUnexpectedChar, "?")
+	testError(t, lept.ErrUnexpectedChar, ".123")
+	testError(t, lept.ErrInvalidValue, "[\"a\", nul]")
+
+	testError(t, lept.ErrPluralRoot, "null x")
+	testError(t, lept.ErrOutOfRange, "1e309")
+	testError(t, lept.ErrOutOfRange, "-1e309")
+
+	testError(t, lept.ErrMissComma, "[1}")
+	testError(t, lept.ErrMissComma, "[1 2")
+	testError(t, lept.ErrMissSquareBracket, "[1,")
+	testError(t, lept.ErrMissKey, "{1:1,")
+	testError(t, lept.ErrMissColon, "{\"a\"}")
+	testError(t, lept.ErrMissComma, "{\"a\":1]")
+	testError(t, lept.ErrMissCurlyBracket, "{\"a\":1,")
+}
+
+func TestSyntaxError(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    error
+		offset  int
+		line    int
+		column  int
+		r       rune
+		context string
+	}{
+		{"{\n  \"a\": 1,\n  \"b\" 2\n}", lept.ErrMissColon, 18, 3, 7, '2', `  "b" 2`},
+		{"[\"日本\", tru]", lept.ErrInvalidValue, 11, 1, 8, 't', `["日本", tru]`},
+		{`"ab\qc"`, lept.ErrInvalidStringEscape, 4, 1, 5, 'q', `"ab\qc"`},
+		{`"\u12G4"`, lept.ErrInvalidUnicodeHex, 5, 1, 6, 'G', `"\u12G4"`},
+		{"[1,\n", lept.ErrMissSquareBracket, 4, 2, 1, lept.EOF, ``},
+		{`{"key": 1} "trailing"`, lept.ErrPluralRoot, 11, 1, 12, '"', `{"key": 1} "trailing"`},
+		{`["0123456789abcdefghijklmnopqrstuvwxyz" 1]`, lept.ErrMissComma, 40, 1, 41, '1', `mnopqrstuvwxyz" 1]`},
+	}
+
+	for _, tt := range tests {
+		_, err := lept.Parse(tt.json)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("parse %q: got error %v want %v", tt.json, err, tt.want)
+			continue
+		}
+		var se *lept.SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("parse %q: got error %T want *lept.SyntaxError", tt.json, err)
+			continue
+		}
+		assertValue(t, se.Offset, tt.offset)
+		assertValue(t, se.Line, tt.line)
+		assertValue(t, se.Column, tt.column)
+		assertValue(t, se.Rune, tt.r)
+		assertValue(t, se.Context, tt.context)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		opts   lept.ParseOptions
+		json   string
+		want   error
+		offset int
+	}{
+		{lept.ParseOptions{MaxDepth: 2}, `[[1], {"a": [2]}]`, lept.ErrDepthLimit, 12},
+		{lept.ParseOptions{MaxDepth: 2}, strings.Repeat("[", 1000000), lept.ErrDepthLimit, 2},
+		{lept.ParseOptions{MaxBytes: 8}, `["abcdef"]`, lept.ErrSizeLimit, 8},
+		{lept.ParseOptions{MaxStringLen: 3}, `["abc", "abcd"]`, lept.ErrStringLimit, 13},
+		{lept.ParseOptions{MaxStringLen: 3}, `"\u0041\u0042\u0043\u0044"`, lept.ErrStringLimit, 25},
+		{lept.ParseOptions{MaxStringLen: 3}, `{"abcd": 1}`, lept.ErrStringLimit, 6},
+		{lept.ParseOptions{MaxStringLen: 3, JSON5: true}, `{abcd: 1}`, lept.ErrStringLimit, 5},
+		{lept.ParseOptions{MaxArrayLen: 2}, `[[1, 2], [1, 2, 3]]`, lept.ErrArrayLimit, 16},
+		{lept.ParseOptions{MaxObjectMembers: 1}, `{"a": 1, "a": 2}`, lept.ErrObjectLimit, 9},
+		{lept.ParseOptions{MaxNumberDigits: 4}, `[1.5e10, -1.2345]`, lept.ErrNumberLimit, 9},
+		{lept.ParseOptions{MaxNumberDigits: 4, JSON5: true}, `0x12345`, lept.ErrNumberLimit, 0},
+	}
+	for _, tt := range tests {
+		_, err := tt.opts.Parse(tt.json)
+		var le *lept.LimitError
+		var se *lept.SyntaxError
+		if !errors.Is(err, tt.want) || !errors.As(err, &le) || !errors.As(err, &se) {
+			t.Errorf("parse %.40q: got error %v want %v", tt.json, err, tt.want)
+			continue
+		}
+		assertValue(t, se.Offset, tt.offset)
+
+		_, err = tt.opts.ParseDocument(tt.json)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("parse document %.40q: got error %v want %v", tt.json, err, tt.want)
+		}
+	}
+
+	opts := lept.ParseOptions{MaxDepth: 2, MaxBytes: 20, MaxStringLen: 3, MaxArrayLen: 2, MaxObjectMembers: 1, MaxNumberDigits: 4}
+	if _, err := opts.Parse(`{"abc": [1.5e1, 2]}`); err != nil {
+		t.Errorf("parse within limits: %v", err)
+	}
+}
+
+func TestParseDeep(t *testing.T) {
+	const depth = 1000000
+	tests := []struct {
+		open, close string
+		next        func(*lept.Value) *lept.Value
+	}{
+		{"[", "]", func(v *lept.Value) *lept.Value { return v.ARRAY().Index(0) }},
+		{`{"a":`, "}", func(v *lept.Value) *lept.Value { return v.Get("a") }},
+	}
+	for _, tt := range tests {
+		json := strings.Repeat(tt.open, depth) + "null" + strings.Repeat(tt.close, depth)
+		v, err := lept.Parse(json)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for ; v.Type != lept.TypeNull; v = tt.next(v) {
+			n++
+		}
+		assertValue(t, n, depth)
+
+		_, err = lept.Parse(json[:len(json)-1])
+		var se *lept.SyntaxError
+		if !errors.As(err, &se) || !errors.Is(err, lept.ErrMissComma) {
+			t.Fatalf("got error %v want %v", err, lept.ErrMissComma)
+		}
+		assertValue(t, se.Offset, len(json)-1)
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	data := []byte(`{"a": "b", "c\u0064": "e\u0066", "g": 1.5}`)
+	aliases := func(s string) bool {
+		p := unsafe.StringData(s)
+		return uintptr(unsafe.Pointer(p))-uintptr(unsafe.Pointer(&data[0])) < uintptr(len(data))
+	}
+
+	v, err := lept.ParseBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, aliases(v.Get("a").STRING()), false)
+	assertValue(t, v.Get("cd").STRING(), "ef")
+
+	v, err = lept.ParseOptions{ZeroCopy: true}.ParseBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := v.OBJECT()
+	assertValue(t, aliases(obj[0].K), true)
+	assertValue(t, aliases(obj[0].V.STRING()), true)
+	assertValue(t, aliases(obj[1].K), false)
+	assertValue(t, aliases(obj[1].V.STRING()), false)
+	assertValue(t, aliases(string(obj[2].V.U.(lept.Number))), true)
+	assertValue(t, v.Get("g").NUMBER(), 1.5)
+
+	_, err = lept.ParseOptions{ZeroCopy: true}.ParseBytes(data[:10])
+	var se *lept.SyntaxError
+	if !errors.As(err, &se) {
+		t.Fatalf("got error %v want *lept.SyntaxError", err)
+	}
+	assertValue(t, aliases(se.Context), false)
+
+	if _, err := lept.ParseBytes(nil); !errors.Is(err, lept.ErrExpectValue) {
+		t.Errorf("got error %v want %v", err, lept.ErrExpectValue)
+	}
+}
+
+func testError(t *testing.T, want error, json string) {
+	t.Helper()
+	_, err := lept.Parse(json)
+	if !errors.Is(err, want) {
+		t.Errorf("parse %q: got error %v want %v", json, err, want)
+	}
+}
+
 func testNumber(t *testing.T, want float64, number string) {
 	v, err := lept.Parse(number)
 	if err != nil {
@@ -189,6 +429,22 @@ func TestObject(t *testing.T) {
 	}
 }
 
+func TestClone(t *testing.T) {
+	v, err := lept.Parse(`{"a": [1, {"b": "c"}], "d": null}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := v.Clone()
+	c.Get("a").Append(lept.NewBool(true))
+	c.Get("a").ARRAY()[1].Set("e", lept.NewNumber(2))
+	c.Set("f", lept.NewString("g"))
+
+	got, _ := lept.Stringify(v)
+	assertValue(t, got, `{"a":[1,{"b":"c"}],"d":null}`)
+	got, _ = lept.Stringify(c)
+	assertValue(t, got, `{"a":[1,{"b":"c","e":2},true],"d":null,"f":"g"}`)
+}
+
 func assertValue[T comparable](t testing.TB, got, want T) {
 	t.Helper()
 	if got != want {
@@ -341,6 +597,63 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+func TestUnmarshalTypeError(t *testing.T) {
+	type Book struct {
+		Author    []string `json:"author"`
+		Publisher struct {
+			Company int `json:"Company"`
+		} `json:"publisher"`
+	}
+
+	tests := []struct {
+		json  string
+		value lept.Type
+		typ   string
+		field string
+		msg   string
+	}{
+		{`{"publisher": {"Company": "Pearson Education"}}`, lept.TypeString, "int", "publisher.Company",
+			"cannot unmarshal STRING into Go struct field publisher.Company of type int"},
+		{`{"author": ["Erich Gamma", 1]}`, lept.TypeNumber, "string", "author[1]",
+			"cannot unmarshal NUMBER into Go struct field author[1] of type string"},
+		{`[]`, lept.TypeArray, "lept_test.Book", "",
+			"cannot unmarshal ARRAY into Go value of type lept_test.Book"},
+	}
+
+	for _, tt := range tests {
+		v, err := lept.Parse(tt.json)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = lept.Unmarshal(v, &Book{})
+		if !errors.Is(err, lept.ErrMismatchType) {
+			t.Errorf("unmarshal %s: got error %v want %v", tt.json, err, lept.ErrMismatchType)
+		}
+		var ute *lept.UnmarshalTypeError
+		if !errors.As(err, &ute) {
+			t.Fatalf("unmarshal %s: got error %T want *lept.UnmarshalTypeError", tt.json, err)
+		}
+		assertValue(t, ute.Value, tt.value)
+		assertValue(t, ute.Type.String(), tt.typ)
+		assertValue(t, ute.Field, tt.field)
+		assertValue(t, ute.Error(), tt.msg)
+	}
+}
+
+func TestUnsupportedType(t *testing.T) {
+	err := lept.Unmarshal(&lept.Value{Type: "UNKNOWN"}, new(any))
+	if !errors.Is(err, lept.ErrUnsupportedType) {
+		t.Errorf("got error %v want %v", err, lept.ErrUnsupportedType)
+	}
+	assertValue(t, err.Error(), `unsupported type: json type "UNKNOWN"`)
+
+	_, err = lept.Marshal(make(chan int))
+	if !errors.Is(err, lept.ErrUnsupportedType) {
+		t.Errorf("got error %v want %v", err, lept.ErrUnsupportedType)
+	}
+	assertValue(t, err.Error(), "unsupported type: Go type chan int")
+}
+
 func ExampleParse() {
 	data := `
 	{
@@ -394,3 +707,57 @@ func BenchmarkParse(b *testing.B) {
 		lept.Parse(data)
 	}
 }
+
+func BenchmarkParseBytes(b *testing.B) {
+	data := []byte(`{"title": "Design Patterns", "author": ["Erich Gamma", "Richard Helm", "Ralph Johnson", "John Vlissides"], "year": 2009, "publisher": {"Company": "Pearson Education", "Country": "India"}}`)
+	for _, opts := range []lept.ParseOptions{{}, {ZeroCopy: true}} {
+		b.Run(fmt.Sprintf("ZeroCopy=%v", opts.ZeroCopy), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				opts.ParseBytes(data)
+			}
+		})
+	}
+}
+
+func BenchmarkParseDeep(b *testing.B) {
+	for _, depth := range []int{100, 10000, 1000000} {
+		data := strings.Repeat("[", depth) + strings.Repeat("]", depth)
+		b.Run(strconv.Itoa(depth), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				lept.Parse(data)
+			}
+		})
+	}
+}
+
+func TestParseDocumentDeep(t *testing.T) {
+	// a parser recursing once per level would overflow this stack
+	defer debug.SetMaxStack(debug.SetMaxStack(16 << 20))
+
+	const depth = 1000000
+	json := strings.Repeat(`[{"a":`, depth/2) + "1" + strings.Repeat("}]", depth/2)
+	d, err := lept.ParseDocument(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for v := d.Root; v.Type != lept.TypeNumber; v = v.Elems[0].Value {
+		n++
+	}
+	assertValue(t, n, depth)
+
+	_, err = lept.ParseDocument(json[:len(json)-1])
+	if !errors.Is(err, lept.ErrMissComma) {
+		t.Fatalf("got error %v want %v", err, lept.ErrMissComma)
+	}
+}
+
+func BenchmarkParseDeepObject(b *testing.B) {
+	data := strings.Repeat(`{"a":`, 10000) + "1" + strings.Repeat("}", 10000)
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		lept.Parse(data)
+	}
+}
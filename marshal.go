@@ -0,0 +1,273 @@
+package lept
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func Marshal(v any) (*Value, error) {
+	m := &marshalState{}
+	return m.marshalValue(reflect.ValueOf(v))
+}
+
+func MarshalString(v any) (string, error) {
+	parsed, err := Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return Stringify(parsed)
+}
+
+type Marshaler interface {
+	MarshalLept() (*Value, error)
+}
+
+var (
+	marshalerType     = reflect.TypeFor[Marshaler]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// startDetectingCyclesAfter is the nesting of pointers, maps and slices past
+// which Marshal looks for cycles, so that the common shallow case needs no
+// bookkeeping.
+const startDetectingCyclesAfter = 1000
+
+type marshalState struct {
+	depth int
+	seen  map[cycleKey]struct{} // pointers, maps and slices being marshaled
+}
+
+type cycleKey struct {
+	ptr uintptr
+	len int // slices with the same array but different lengths differ
+}
+
+func newCycleKey(v reflect.Value) cycleKey {
+	if v.Kind() == reflect.Slice {
+		return cycleKey{v.Pointer(), v.Len()}
+	}
+	return cycleKey{v.Pointer(), 0}
+}
+
+// enter records that the contents of v, a pointer, map or slice, are being
+// marshaled, failing if v is already being marshaled further up. The caller
+// defers leave.
+func (m *marshalState) enter(v reflect.Value) error {
+	m.depth++
+	if m.depth <= startDetectingCyclesAfter {
+		return nil
+	}
+	if m.seen == nil {
+		m.seen = map[cycleKey]struct{}{}
+	}
+	k := newCycleKey(v)
+	if _, ok := m.seen[k]; ok {
+		return errorf("%w: cycle through %s", ErrUnsupportedValue, v.Type())
+	}
+	m.seen[k] = struct{}{}
+	return nil
+}
+
+func (m *marshalState) leave(v reflect.Value) {
+	if m.depth > startDetectingCyclesAfter {
+		delete(m.seen, newCycleKey(v))
+	}
+	m.depth--
+}
+
+func (m *marshalState) marshalValue(v reflect.Value) (*Value, error) {
+	if !v.IsValid() {
+		return NewNull(), nil
+	}
+
+	if m, ok := implements(v, marshalerType); ok {
+		e, err := m.(Marshaler).MarshalLept()
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			return NewNull(), nil
+		}
+		return e, nil
+	}
+	if m, ok := implements(v, jsonMarshalerType); ok {
+		data, err := m.(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		return Parse(string(data))
+	}
+	if m, ok := implements(v, textMarshalerType); ok {
+		text, err := m.(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return NewString(string(text)), nil
+	}
+
+	if v.Type() == numberType {
+		n := Number(v.String())
+		if n == "" {
+			n = "0"
+		}
+		if !isNumber(string(n)) {
+			return nil, errorf("%w: number %q", ErrUnsupportedValue, n)
+		}
+		return &Value{n, TypeNumber}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return NewBool(v.Bool()), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return &Value{Number(strconv.FormatInt(v.Int(), 10)), TypeNumber}, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		return &Value{Number(strconv.FormatUint(v.Uint(), 10)), TypeNumber}, nil
+	case reflect.Float64, reflect.Float32:
+		buf, err := appendFloat(nil, v.Float(), v.Type().Bits())
+		if err != nil {
+			return nil, err
+		}
+		return &Value{Number(buf), TypeNumber}, nil
+	case reflect.String:
+		return NewString(v.String()), nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return NewNull(), nil
+		}
+		return m.marshalValue(v.Elem())
+	case reflect.Pointer:
+		if v.IsNil() {
+			return NewNull(), nil
+		}
+		defer m.leave(v)
+		if err := m.enter(v); err != nil {
+			return nil, err
+		}
+		return m.marshalValue(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return NewNull(), nil
+		}
+		defer m.leave(v)
+		if err := m.enter(v); err != nil {
+			return nil, err
+		}
+		return m.marshalArray(v)
+	case reflect.Array:
+		return m.marshalArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return NewNull(), nil
+		}
+		defer m.leave(v)
+		if err := m.enter(v); err != nil {
+			return nil, err
+		}
+		return m.marshalMap(v)
+	case reflect.Struct:
+		return m.marshalStruct(v)
+	default:
+		return nil, errUnsupportedType(v.Interface())
+	}
+}
+
+func (m *marshalState) marshalArray(v reflect.Value) (*Value, error) {
+	arr := make(Array, v.Len())
+	for i := range arr {
+		e, err := m.marshalValue(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = e
+	}
+	return &Value{arr, TypeArray}, nil
+}
+
+func (m *marshalState) marshalMap(v reflect.Value) (*Value, error) {
+	obj := make(Object, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k, err := marshalKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		e, err := m.marshalValue(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		obj = append(obj, Member{k, e})
+	}
+
+	// map iteration order is random, sort to keep the output stable
+	slices.SortFunc(obj, func(a, b Member) int {
+		return strings.Compare(a.K, b.K)
+	})
+	return &Value{obj, TypeObject}, nil
+}
+
+// implements reports whether v, or its address when v is addressable,
+// implements t. Nil pointers do not count, they are marshaled as null.
+func implements(v reflect.Value, t reflect.Type) (any, bool) {
+	if v.Kind() == reflect.Interface || !v.CanInterface() {
+		return nil, false
+	}
+	if v.Type().Implements(t) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil, false
+		}
+		return v.Interface(), true
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(t) {
+		return v.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+func marshalKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if m, ok := implements(k, textMarshalerType); ok {
+		text, err := m.(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch k.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	default:
+		return "", errUnsupportedType(k.Interface())
+	}
+}
+
+func (m *marshalState) marshalStruct(v reflect.Value) (*Value, error) {
+	obj := Object{}
+	for _, f := range cachedFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok || f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+
+		e, err := m.marshalValue(fv)
+		if err != nil {
+			return nil, err
+		}
+		if f.asString && e.Type != TypeNull {
+			s, err := Stringify(e)
+			if err != nil {
+				return nil, err
+			}
+			e = NewString(s)
+		}
+		obj = append(obj, Member{f.name, e})
+	}
+	return &Value{obj, TypeObject}, nil
+}
@@ -0,0 +1,140 @@
+package lept_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+type book struct {
+	Title     string    `json:"title"`
+	Subtitle  string    `json:"subtitle"`
+	Author    [4]string `json:"author"`
+	Year      int       `json:"year"`
+	Weight    float64   `json:"weight"`
+	Hardcover bool      `json:"hardcover"`
+	Publisher struct {
+		Company string `json:"Company"`
+		Country string `json:"Country"`
+	} `json:"publisher"`
+	Tags    []string `json:"tags"`
+	Website *string  `json:"website"`
+	skipped int
+	Ignored int `json:"-"`
+}
+
+func TestMarshal(t *testing.T) {
+	b := book{
+		Title:     "Design Patterns",
+		Subtitle:  "Elements of Reusable Object-Oriented Software",
+		Author:    [4]string{"Erich Gamma", "Richard Helm", "Ralph Johnson", "John Vlissides"},
+		Year:      2009,
+		Weight:    1.8,
+		Hardcover: true,
+		Tags:      []string{"oop"},
+		skipped:   1,
+		Ignored:   2,
+	}
+	b.Publisher.Company = "Pearson Education"
+	b.Publisher.Country = "India"
+
+	got, err := lept.MarshalString(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, got, `{"title":"Design Patterns","subtitle":"Elements of Reusable Object-Oriented Software","author":["Erich Gamma","Richard Helm","Ralph Johnson","John Vlissides"],"year":2009,"weight":1.8,"hardcover":true,"publisher":{"Company":"Pearson Education","Country":"India"},"tags":["oop"],"website":null}`)
+
+	v, err := lept.Marshal(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := lept.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, v.String(), expected.String())
+
+	decoded := book{}
+	if err := lept.Unmarshal(v, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	b.skipped, b.Ignored = 0, 0
+	if !reflect.DeepEqual(decoded, b) {
+		t.Errorf("got %v want %v", decoded, b)
+	}
+}
+
+func TestMarshalKinds(t *testing.T) {
+	s := "s"
+	var iface any = []any{int8(-8), uint16(16), float32(1.5), nil}
+
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{nil, `null`},
+		{true, `true`},
+		{-42, `-42`},
+		{uint64(42), `42`},
+		{3.25, `3.25`},
+		{"a\"b", `"a\"b"`},
+		{&s, `"s"`},
+		{(*string)(nil), `null`},
+		{[]int(nil), `null`},
+		{[]int{}, `[]`},
+		{[2]bool{true, false}, `[true,false]`},
+		{iface, `[-8,16,1.5,null]`},
+		{map[string]int{"b": 2, "a": 1}, `{"a":1,"b":2}`},
+		{map[int]string{2: "b", 1: "a"}, `{"1":"a","2":"b"}`},
+		{map[string]any(nil), `null`},
+	}
+
+	for _, tt := range tests {
+		got, err := lept.MarshalString(tt.in)
+		if err != nil {
+			t.Errorf("marshal %#v failed: %v", tt.in, err)
+			continue
+		}
+		assertValue(t, got, tt.want)
+	}
+}
+
+type listNode struct {
+	Value int
+	Next  *listNode
+}
+
+func TestMarshalCycle(t *testing.T) {
+	n := &listNode{}
+	n.Next = n
+	m := map[string]any{}
+	m["m"] = m
+	s := []any{nil}
+	s[0] = s
+	for _, in := range []any{n, m, s} {
+		if _, err := lept.Marshal(in); !errors.Is(err, lept.ErrUnsupportedValue) {
+			t.Errorf("marshal %T: got error %v want %v", in, err, lept.ErrUnsupportedValue)
+		}
+	}
+
+	// long chains and shared pointers are not cycles
+	var list *listNode
+	for i := range 3000 {
+		list = &listNode{i, list}
+	}
+	shared := []*listNode{list, list}
+	if _, err := lept.Marshal(shared); err != nil {
+		t.Errorf("marshal list: %v", err)
+	}
+}
+
+func TestMarshalUnsupported(t *testing.T) {
+	for _, in := range []any{make(chan int), func() {}, complex(1, 2), math.NaN(), map[float64]int{1: 1}} {
+		if _, err := lept.Marshal(in); err == nil {
+			t.Errorf("marshal %T: expected error", in)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package lept
+
+import "slices"
+
+// MergePatch applies an RFC 7396 JSON Merge Patch to a copy of target and
+// returns it. Members of patch set to null are deleted, existing members keep
+// their position in the target and new members are appended. A nil target is
+// treated as absent. Neither argument is modified.
+func MergePatch(target, patch *Value) *Value {
+	if target != nil {
+		target = target.Clone()
+	}
+	return mergePatch(target, patch)
+}
+
+func mergePatch(target, patch *Value) *Value {
+	if patch.Type != TypeObject {
+		return patch.Clone()
+	}
+	if target == nil || target.Type != TypeObject {
+		target = NewObject()
+	}
+
+	obj := target.OBJECT()
+	for _, m := range patch.OBJECT() {
+		if m.V.Type == TypeNull {
+			obj = slices.DeleteFunc(obj, func(e Member) bool {
+				return e.K == m.K
+			})
+			continue
+		}
+		i := len(obj) - 1
+		for i >= 0 && obj[i].K != m.K {
+			i--
+		}
+		if i < 0 {
+			obj = append(obj, Member{m.K, mergePatch(nil, m.V)})
+		} else {
+			obj[i].V = mergePatch(obj[i].V, m.V)
+		}
+	}
+	target.U = obj
+	return target
+}
+
+// CreateMergePatch returns a merge patch that turns original into modified.
+// As merge patches use null for deletion, null members of modified cannot be
+// expressed and are deleted when the patch is applied.
+func CreateMergePatch(original, modified *Value) *Value {
+	if original.Type != TypeObject || modified.Type != TypeObject {
+		return modified.Clone()
+	}
+
+	patch := NewObject()
+	a, b := original.OBJECT(), modified.OBJECT()
+	seen := map[string]bool{}
+	for _, m := range a {
+		if seen[m.K] {
+			continue
+		}
+		seen[m.K] = true
+		av, bv := a.Get(m.K), b.Get(m.K)
+		switch {
+		case bv == nil:
+			patch.Set(m.K, NewNull())
+		case !Equal(av, bv):
+			patch.Set(m.K, CreateMergePatch(av, bv))
+		}
+	}
+	for _, m := range b {
+		if !seen[m.K] {
+			seen[m.K] = true
+			patch.Set(m.K, b.Get(m.K).Clone())
+		}
+	}
+	return patch
+}
@@ -0,0 +1,76 @@
+package lept_test
+
+import (
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func TestMergePatch(t *testing.T) {
+	// RFC 7396, Appendix A
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+
+		{`{"z":1,"a":2,"m":3}`, `{"a":{"x":null,"y":1},"z":null,"n":4}`, `{"a":{"y":1},"m":3,"n":4}`},
+		{`{"a":1,"a":2}`, `{"a":3}`, `{"a":1,"a":3}`},
+		{`{"a":1,"a":2}`, `{"a":null}`, `{}`},
+	}
+	for _, tt := range tests {
+		target := mustParse(t, tt.target)
+		got, _ := lept.Stringify(lept.MergePatch(target, mustParse(t, tt.patch)))
+		assertValue(t, got, tt.want)
+
+		s, _ := lept.Stringify(target)
+		orig, _ := lept.Stringify(mustParse(t, tt.target))
+		assertValue(t, s, orig)
+	}
+
+	got, _ := lept.Stringify(lept.MergePatch(nil, mustParse(t, `{"a":{"b":null,"c":1}}`)))
+	assertValue(t, got, `{"a":{"c":1}}`)
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		original string
+		modified string
+		want     string
+	}{
+		{`{"a":"b"}`, `{"a":"b"}`, `{}`},
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b","b":"c"}`, `{"b":"c"}`, `{"a":null}`},
+		{`{"a":"b"}`, `{"a":"b","c":[1]}`, `{"c":[1]}`},
+		{`{"a":{"b":"c","d":"e"}}`, `{"a":{"b":"c","f":1}}`, `{"a":{"d":null,"f":1}}`},
+		{`{"a":[1,2]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"a":1}`, `{"a":1.0}`, `{}`},
+		{`{"a":1}`, `[1]`, `[1]`},
+		{`[1]`, `{"a":1}`, `{"a":1}`},
+		{`{"a":{"b":1}}`, `{"a":5}`, `{"a":5}`},
+	}
+	for _, tt := range tests {
+		original, modified := mustParse(t, tt.original), mustParse(t, tt.modified)
+		patch := lept.CreateMergePatch(original, modified)
+		got, _ := lept.Stringify(patch)
+		assertValue(t, got, tt.want)
+
+		merged := lept.MergePatch(original, patch)
+		assertValue(t, len(lept.Diff(merged, modified).ARRAY()), 0)
+	}
+}
@@ -0,0 +1,250 @@
+package lept
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var ErrNotInteger = errors.New("number is not an integer")
+
+// maxBigDigits bounds the integers BigInt expands exponents into, so that a
+// short literal like 1e999999999 cannot force a huge allocation.
+const maxBigDigits = 10000
+
+// Number is the literal text of a JSON number, kept so that values which do
+// not fit a float64 survive decoding and re-encoding unchanged.
+type Number string
+
+func (n Number) String() string {
+	return string(n)
+}
+
+func (n Number) Float64() (float64, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return f, ErrOutOfRange
+		}
+		return 0, ErrInvalidValue
+	}
+	return f, nil
+}
+
+func (n Number) Int64() (int64, error) {
+	neg, digits, err := n.integer(19)
+	if err != nil {
+		return 0, err
+	}
+	u, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, ErrOutOfRange
+	}
+	if neg {
+		if u > 1<<63 {
+			return 0, ErrOutOfRange
+		}
+		return -int64(u), nil
+	}
+	if u > math.MaxInt64 {
+		return 0, ErrOutOfRange
+	}
+	return int64(u), nil
+}
+
+func (n Number) Uint64() (uint64, error) {
+	neg, digits, err := n.integer(20)
+	if err != nil {
+		return 0, err
+	}
+	if neg && digits != "0" {
+		return 0, ErrOutOfRange
+	}
+	u, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, ErrOutOfRange
+	}
+	return u, nil
+}
+
+func (n Number) BigInt() (*big.Int, error) {
+	neg, digits, err := n.integer(maxBigDigits)
+	if err != nil {
+		return nil, err
+	}
+	i, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		i.Neg(i)
+	}
+	return i, nil
+}
+
+func (n Number) BigFloat() (*big.Float, error) {
+	neg, mant, _, ok := n.decimal()
+	if !ok {
+		return nil, ErrInvalidValue
+	}
+	prec := max(64, uint(len(mant))*4)
+	f, _, err := big.ParseFloat(string(n), 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, ErrOutOfRange
+	}
+	if neg && f.Sign() == 0 {
+		f.Neg(f)
+	}
+	return f, nil
+}
+
+// integer returns the decimal digits of n when it is an integer of at most
+// maxDigits digits, expanding any exponent.
+func (n Number) integer(maxDigits int) (bool, string, error) {
+	neg, mant, exp, ok := n.decimal()
+	if !ok {
+		return false, "", ErrInvalidValue
+	}
+	if mant == "0" {
+		return neg, "0", nil
+	}
+	if exp < 0 {
+		return false, "", ErrNotInteger
+	}
+	if len(mant)+exp > maxDigits {
+		return false, "", ErrOutOfRange
+	}
+	return neg, mant + strings.Repeat("0", exp), nil
+}
+
+// decimal splits n into sign, significant digits without leading or trailing
+// zeros ("0" for zero), and the power of ten they are scaled by.
+func (n Number) decimal() (neg bool, mant string, exp int, ok bool) {
+	s := string(n)
+	if !isNumber(s) {
+		return false, "", 0, false
+	}
+	if s[0] == '-' {
+		neg = true
+		s = s[1:]
+	}
+
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil {
+			// the exponent overflows int, clamp it to something still
+			// far beyond any representable value
+			e = math.MaxInt32
+			if s[i+1] == '-' {
+				e = -e
+			}
+		}
+		exp = e
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		exp -= len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+
+	s = strings.TrimLeft(s, "0")
+	if s == "" {
+		return neg, "0", 0, true
+	}
+	trimmed := strings.TrimRight(s, "0")
+	exp += len(s) - len(trimmed)
+	return neg, trimmed, exp, true
+}
+
+// isNumber reports whether s follows the JSON number grammar.
+func isNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(s) && s[i] == '0':
+		i++
+	case i < len(s) && s[i] >= '1' && s[i] <= '9':
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+	default:
+		return false
+	}
+
+	if i < len(s) && s[i] == '.' {
+		i++
+		if i == len(s) || !isDigit(s[i]) {
+			return false
+		}
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+	}
+
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if i == len(s) || !isDigit(s[i]) {
+			return false
+		}
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+	}
+	return i == len(s)
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// number returns the literal of a TypeNumber value, formatting values built
+// from Go numbers the way Stringify writes them.
+func (v *Value) number() (Number, error) {
+	if v.Type != TypeNumber {
+		return "", ErrMismatchType
+	}
+	if n, ok := v.U.(Number); ok {
+		return n, nil
+	}
+	buf, err := appendNumber(nil, v.U)
+	if err != nil {
+		return "", err
+	}
+	return Number(buf), nil
+}
+
+func (v *Value) Int64() (int64, error) {
+	n, err := v.number()
+	if err != nil {
+		return 0, err
+	}
+	return n.Int64()
+}
+
+func (v *Value) Uint64() (uint64, error) {
+	n, err := v.number()
+	if err != nil {
+		return 0, err
+	}
+	return n.Uint64()
+}
+
+func (v *Value) BigInt() (*big.Int, error) {
+	n, err := v.number()
+	if err != nil {
+		return nil, err
+	}
+	return n.BigInt()
+}
+
+func (v *Value) BigFloat() (*big.Float, error) {
+	n, err := v.number()
+	if err != nil {
+		return nil, err
+	}
+	return n.BigFloat()
+}
@@ -0,0 +1,228 @@
+package lept_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func TestNumberLiteral(t *testing.T) {
+	for _, json := range []string{
+		`12345678901234567890123`,
+		`9007199254740993`,
+		`-0.000000000000000000000001`,
+		`1.5E+300`,
+		`[18446744073709551615,-9223372036854775808]`,
+		`{"id":123456789012345678}`,
+	} {
+		testRoundtrip(t, json)
+	}
+}
+
+func TestNumberInt(t *testing.T) {
+	tests := []struct {
+		json string
+		i    int64
+		u    uint64
+		ierr error
+		uerr error
+	}{
+		{"0", 0, 0, nil, nil},
+		{"-0", 0, 0, nil, nil},
+		{"42", 42, 42, nil, nil},
+		{"-42", -42, 0, nil, lept.ErrOutOfRange},
+		{"1e3", 1000, 1000, nil, nil},
+		{"1.000", 1, 1, nil, nil},
+		{"12.5e1", 125, 125, nil, nil},
+		{"0.5", 0, 0, lept.ErrNotInteger, lept.ErrNotInteger},
+		{"3.7", 0, 0, lept.ErrNotInteger, lept.ErrNotInteger},
+		{"1e-1", 0, 0, lept.ErrNotInteger, lept.ErrNotInteger},
+		{"9007199254740993", 9007199254740993, 9007199254740993, nil, nil},
+		{"9223372036854775807", math.MaxInt64, math.MaxInt64, nil, nil},
+		{"9223372036854775808", 0, 1 << 63, lept.ErrOutOfRange, nil},
+		{"-9223372036854775808", math.MinInt64, 0, nil, lept.ErrOutOfRange},
+		{"18446744073709551615", 0, math.MaxUint64, lept.ErrOutOfRange, nil},
+		{"18446744073709551616", 0, 0, lept.ErrOutOfRange, lept.ErrOutOfRange},
+		{"1e100", 0, 0, lept.ErrOutOfRange, lept.ErrOutOfRange},
+	}
+
+	for _, tt := range tests {
+		v, err := lept.Parse(tt.json)
+		if err != nil {
+			t.Fatalf("parse %s failed: %v", tt.json, err)
+		}
+		i, err := v.Int64()
+		if !errors.Is(err, tt.ierr) || i != tt.i {
+			t.Errorf("%s.Int64(): got %d, %v want %d, %v", tt.json, i, err, tt.i, tt.ierr)
+		}
+		u, err := v.Uint64()
+		if !errors.Is(err, tt.uerr) || u != tt.u {
+			t.Errorf("%s.Uint64(): got %d, %v want %d, %v", tt.json, u, err, tt.u, tt.uerr)
+		}
+	}
+
+	if _, err := lept.NewString("1").Int64(); !errors.Is(err, lept.ErrMismatchType) {
+		t.Errorf("got error %v want %v", err, lept.ErrMismatchType)
+	}
+	for _, n := range []lept.Number{"1e99999999999999999999", "1e-99999999999999999999"} {
+		if _, err := n.Int64(); err == nil {
+			t.Errorf("%s.Int64(): expected error", n)
+		}
+	}
+	if _, err := lept.Number("1.").Int64(); !errors.Is(err, lept.ErrInvalidValue) {
+		t.Errorf("got error %v want %v", err, lept.ErrInvalidValue)
+	}
+
+	i, err := lept.NewNumber(1e15).Int64()
+	if err != nil || i != 1e15 {
+		t.Errorf("got %d, %v want %d", i, err, int64(1e15))
+	}
+}
+
+func TestNumberBig(t *testing.T) {
+	v, err := lept.Parse(`[123456789012345678901234567890, -1.5e30, 2.5, 0.1]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := v.ARRAY()
+
+	i, err := arr[0].BigInt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, i.String(), "123456789012345678901234567890")
+	i, err = arr[1].BigInt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, i.String(), "-1500000000000000000000000000000")
+	if _, err := arr[2].BigInt(); !errors.Is(err, lept.ErrNotInteger) {
+		t.Errorf("got error %v want %v", err, lept.ErrNotInteger)
+	}
+
+	f, err := arr[0].BigFloat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, f.Text('f', 0), "123456789012345678901234567890")
+	f, err = arr[3].BigFloat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _, _ := big.ParseFloat("0.1", 10, f.Prec(), big.ToNearestEven)
+	assertValue(t, f.Cmp(want), 0)
+}
+
+func TestUnmarshalNumber(t *testing.T) {
+	type T struct {
+		I   int8        `json:"i"`
+		U   uint        `json:"u"`
+		F   float32     `json:"f"`
+		N   lept.Number `json:"n"`
+		Big *big.Int    `json:"big"`
+	}
+
+	v, err := lept.Parse(`{"i": -128, "u": 1e3, "f": 1.5, "n": 12345678901234567890, "big": 123456789012345678901234567890}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := T{}
+	if err := lept.Unmarshal(v, &got); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, got.I, int8(-128))
+	assertValue(t, got.U, uint(1000))
+	assertValue(t, got.F, float32(1.5))
+	assertValue(t, got.N, lept.Number("12345678901234567890"))
+	assertValue(t, got.Big.String(), "123456789012345678901234567890")
+
+	s, err := lept.MarshalString(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, s, `{"i":-128,"u":1000,"f":1.5,"n":12345678901234567890,"big":123456789012345678901234567890}`)
+
+	tests := []struct {
+		json string
+		want error
+	}{
+		{`{"i": 3.7}`, lept.ErrNotInteger},
+		{`{"i": 128}`, lept.ErrOutOfRange},
+		{`{"u": -1}`, lept.ErrOutOfRange},
+		{`{"f": 1e39}`, lept.ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		v, err := lept.Parse(tt.json)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = lept.Unmarshal(v, &T{})
+		if !errors.Is(err, tt.want) || !errors.Is(err, lept.ErrMismatchType) {
+			t.Errorf("unmarshal %s: got error %v want %v", tt.json, err, tt.want)
+		}
+	}
+}
+
+func TestUseNumber(t *testing.T) {
+	v, err := lept.Parse(`{"id": 12345678901234567890, "list": [1.50]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got any
+	if err := (lept.DecodeOptions{UseNumber: true}).Unmarshal(v, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"id": lept.Number("12345678901234567890"), "list": []any{lept.Number("1.50")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v want %#v", got, want)
+	}
+
+	if err := lept.Unmarshal(v, &got); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, got.(map[string]any)["id"], any(1.2345678901234567e19))
+
+	dec := lept.NewDecoder(strings.NewReader(`{"id": 12345678901234567890, "list": [1.50]}`))
+	dec.UseNumber()
+	got = nil
+	if err := dec.Unmarshal(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoder: got %#v want %#v", got, want)
+	}
+}
+
+func TestMarshalNumber(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{int64(math.MaxInt64), "9223372036854775807"},
+		{uint64(math.MaxUint64), "18446744073709551615"},
+		{float32(0.1), "0.1"},
+		{0.1, "0.1"},
+		{1e21, "1e+21"},
+		{lept.Number(""), "0"},
+		{lept.Number("-1.5e10"), "-1.5e10"},
+	}
+	for _, tt := range tests {
+		got, err := lept.MarshalString(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertValue(t, got, tt.want)
+	}
+
+	for _, in := range []any{lept.Number("1."), lept.Number("0x10"), float32(math.Inf(1))} {
+		if _, err := lept.Marshal(in); !errors.Is(err, lept.ErrUnsupportedValue) {
+			t.Errorf("marshal %v: got error %v want %v", in, err, lept.ErrUnsupportedValue)
+		}
+	}
+}
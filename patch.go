@@ -0,0 +1,251 @@
+package lept
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+)
+
+var ErrInvalidPatch = errors.New("invalid json patch")
+var ErrTestFailed = errors.New("json patch test failed")
+
+// ApplyPatch applies an RFC 6902 JSON Patch to a copy of doc and returns it.
+// The patch is atomic: if any operation fails, the error is returned and no
+// result is produced. doc itself is never modified.
+func ApplyPatch(doc *Value, patch *Value) (*Value, error) {
+	if patch.Type != TypeArray {
+		return nil, errorf("%w: patch must be an array", ErrInvalidPatch)
+	}
+
+	doc = doc.Clone()
+	for i, op := range patch.ARRAY() {
+		if err := applyOperation(doc, op); err != nil {
+			return nil, errorf("operation %d: %w", i, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyOperation(doc *Value, op *Value) error {
+	if op.Type != TypeObject {
+		return errorf("%w: operation must be an object", ErrInvalidPatch)
+	}
+	name, err := patchString(op, "op")
+	if err != nil {
+		return err
+	}
+	path, err := patchPointer(op, "path")
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "add":
+		val, err := patchValue(op)
+		if err != nil {
+			return err
+		}
+		return path.Insert(doc, val.Clone())
+	case "remove":
+		_, err := path.Remove(doc)
+		return err
+	case "replace":
+		val, err := patchValue(op)
+		if err != nil {
+			return err
+		}
+		if _, err := path.Get(doc); err != nil {
+			return err
+		}
+		return path.Set(doc, val.Clone())
+	case "move":
+		from, err := patchPointer(op, "from")
+		if err != nil {
+			return err
+		}
+		if len(from) < len(path) && slices.Equal(path[:len(from)], from) {
+			return errorf("%w: cannot move %s into itself", ErrInvalidPatch, from)
+		}
+		val, err := from.Remove(doc)
+		if err != nil {
+			return err
+		}
+		return path.Insert(doc, val)
+	case "copy":
+		from, err := patchPointer(op, "from")
+		if err != nil {
+			return err
+		}
+		val, err := from.Get(doc)
+		if err != nil {
+			return err
+		}
+		return path.Insert(doc, val.Clone())
+	case "test":
+		val, err := patchValue(op)
+		if err != nil {
+			return err
+		}
+		target, err := path.Get(doc)
+		if err != nil {
+			return err
+		}
+		if !Equal(target, val) {
+			return errorf("%w: %s", ErrTestFailed, path)
+		}
+		return nil
+	default:
+		return errorf("%w: unknown op %q", ErrInvalidPatch, name)
+	}
+}
+
+func patchString(op *Value, k string) (string, error) {
+	v := op.Get(k)
+	if v == nil || v.Type != TypeString {
+		return "", errorf("%w: %q must be a string", ErrInvalidPatch, k)
+	}
+	return v.STRING(), nil
+}
+
+func patchPointer(op *Value, k string) (Pointer, error) {
+	s, err := patchString(op, k)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePointer(s)
+}
+
+func patchValue(op *Value) (*Value, error) {
+	v := op.Get("value")
+	if v == nil {
+		return nil, errorf("%w: missing \"value\"", ErrInvalidPatch)
+	}
+	return v, nil
+}
+
+// maxDiffCells bounds the table used to align two arrays in Diff. Larger
+// arrays are compared element by element.
+const maxDiffCells = 1 << 20
+
+// Diff returns a JSON Patch that turns a into b. Equal subtrees produce no
+// operations, objects are diffed member by member and arrays by their longest
+// common subsequence. Diff does not emit move or copy operations.
+func Diff(a, b *Value) *Value {
+	patch := NewArray()
+	diff(patch, Pointer{}, a, b)
+	return patch
+}
+
+func diff(patch *Value, p Pointer, a, b *Value) {
+	if Equal(a, b) {
+		return
+	}
+	switch {
+	case a.Type == TypeObject && b.Type == TypeObject:
+		diffObject(patch, p, a.OBJECT(), b.OBJECT())
+	case a.Type == TypeArray && b.Type == TypeArray:
+		diffArray(patch, p, a.ARRAY(), b.ARRAY())
+	default:
+		patch.Append(operation("replace", p, b))
+	}
+}
+
+func diffObject(patch *Value, p Pointer, a, b Object) {
+	seen := map[string]bool{}
+	for _, m := range a {
+		if seen[m.K] {
+			continue
+		}
+		seen[m.K] = true
+		if bv := b.Get(m.K); bv == nil {
+			patch.Append(operation("remove", p.child(m.K), nil))
+		} else {
+			diff(patch, p.child(m.K), a.Get(m.K), bv)
+		}
+	}
+	for _, m := range b {
+		if !seen[m.K] {
+			seen[m.K] = true
+			patch.Append(operation("add", p.child(m.K), b.Get(m.K)))
+		}
+	}
+}
+
+func diffArray(patch *Value, p Pointer, a, b Array) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && Equal(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && Equal(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// Each gap between matched elements turns a run of a into a run of b:
+	// paired elements are diffed in place, then the rest is removed or added.
+	i, j := 0, 0
+	gap := func(ai, bj int) {
+		n := min(ai-i, bj-j)
+		for k := range n {
+			diff(patch, p.child(strconv.Itoa(prefix+j+k)), a[i+k], b[j+k])
+		}
+		for range ai - i - n {
+			patch.Append(operation("remove", p.child(strconv.Itoa(prefix+j+n)), nil))
+		}
+		for k := n; k < bj-j; k++ {
+			patch.Append(operation("add", p.child(strconv.Itoa(prefix+j+k)), b[j+k]))
+		}
+	}
+	for _, m := range commonSubsequence(a, b) {
+		gap(m[0], m[1])
+		i, j = m[0]+1, m[1]+1
+	}
+	gap(len(a), len(b))
+}
+
+// commonSubsequence returns the index pairs of a longest common subsequence
+// of a and b.
+func commonSubsequence(a, b Array) [][2]int {
+	if len(a)*len(b) > maxDiffCells {
+		return nil
+	}
+	w := len(b) + 1
+	lcs := make([]int, (len(a)+1)*w)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if Equal(a[i], b[j]) {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+
+	var pairs [][2]int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case Equal(a[i], b[j]):
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+func (p Pointer) child(t string) Pointer {
+	return append(p[:len(p):len(p)], t)
+}
+
+func operation(op string, p Pointer, val *Value) *Value {
+	o := NewObject().Set("op", NewString(op)).Set("path", NewString(p.String()))
+	if val != nil {
+		o.Set("value", val.Clone())
+	}
+	return o
+}
@@ -0,0 +1,161 @@
+package lept_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func mustParse(t *testing.T, json string) *lept.Value {
+	t.Helper()
+	v, err := lept.Parse(json)
+	if err != nil {
+		t.Fatalf("parse %s failed: %v", json, err)
+	}
+	return v
+}
+
+func TestApplyPatch(t *testing.T) {
+	// RFC 6902, Appendix A
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`, `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz": "qux", "foo": ["a", 2, "c"]}`, `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}]`, `{"/":9,"~1":10}`},
+		{`{"foo": {"a": 1, "b": [1.0, {"c": null}]}}`, `[{"op": "test", "path": "/foo", "value": {"b": [1, {"c": null}], "a": 1e0}}]`, `{"foo":{"a":1,"b":[1.0,{"c":null}]}}`},
+		{`{"foo": 1}`, `[{"op": "copy", "from": "/foo", "path": "/bar"}, {"op": "replace", "path": "", "value": [1]}]`, `[1]`},
+		{`{"foo": {"bar": 1}}`, `[{"op": "move", "from": "/foo", "path": "/foo"}]`, `{"foo":{"bar":1}}`},
+		{`[]`, `[]`, `[]`},
+	}
+	for _, tt := range tests {
+		doc := mustParse(t, tt.doc)
+		orig, _ := lept.Stringify(doc)
+		got, err := lept.ApplyPatch(doc, mustParse(t, tt.patch))
+		if err != nil {
+			t.Errorf("patch %s failed: %v", tt.patch, err)
+			continue
+		}
+		s, _ := lept.Stringify(got)
+		assertValue(t, s, tt.want)
+		s, _ = lept.Stringify(doc)
+		assertValue(t, s, orig)
+	}
+}
+
+func TestApplyPatchError(t *testing.T) {
+	tests := []struct {
+		doc   string
+		patch string
+		want  error
+	}{
+		{`{"baz": "qux"}`, `[{"op": "test", "path": "/baz", "value": "bar"}]`, lept.ErrTestFailed},
+		{`{"foo": ["bar", "baz"]}`, `[{"op": "test", "path": "/foo", "value": ["baz", "bar"]}]`, lept.ErrTestFailed},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, lept.ErrPointerNotFound},
+		{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/2", "value": "qux"}]`, lept.ErrPointerNotFound},
+		{`{"foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "qux"}]`, lept.ErrPointerNotFound},
+		{`{"foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, lept.ErrPointerNotFound},
+		{`{"foo": {"bar": 1}}`, `[{"op": "move", "from": "/foo", "path": "/foo/bar/baz"}]`, lept.ErrInvalidPatch},
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz"}]`, lept.ErrInvalidPatch},
+		{`{"foo": "bar"}`, `[{"op": "spam", "path": "/baz"}]`, lept.ErrInvalidPatch},
+		{`{"foo": "bar"}`, `[{"path": "/baz"}]`, lept.ErrInvalidPatch},
+		{`{"foo": "bar"}`, `[{"op": "copy", "path": "/baz"}]`, lept.ErrInvalidPatch},
+		{`{"foo": "bar"}`, `[{"op": "remove", "path": "baz"}]`, lept.ErrPointerSyntax},
+		{`{"foo": "bar"}`, `[1]`, lept.ErrInvalidPatch},
+		{`{"foo": "bar"}`, `{"op": "remove", "path": "/foo"}`, lept.ErrInvalidPatch},
+	}
+	for _, tt := range tests {
+		_, err := lept.ApplyPatch(mustParse(t, tt.doc), mustParse(t, tt.patch))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("patch %s: got error %v want %v", tt.patch, err, tt.want)
+		}
+	}
+
+	// a failing operation leaves the document as it was
+	doc := mustParse(t, `{"a": [1, 2]}`)
+	_, err := lept.ApplyPatch(doc, mustParse(t, `[{"op": "remove", "path": "/a/0"}, {"op": "test", "path": "/a/0", "value": 1}]`))
+	if !errors.Is(err, lept.ErrTestFailed) {
+		t.Fatalf("got error %v want %v", err, lept.ErrTestFailed)
+	}
+	s, _ := lept.Stringify(doc)
+	assertValue(t, s, `{"a":[1,2]}`)
+}
+
+func TestApplyPatchNilElement(t *testing.T) {
+	doc := lept.NewObject().Set("a", lept.NewArray().Append(nil, lept.NewNumber(1))).Set("b", nil)
+	got, err := lept.ApplyPatch(doc, mustParse(t, `[{"op": "replace", "path": "/a/1", "value": 2}, {"op": "copy", "from": "/a", "path": "/c"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := lept.Stringify(got)
+	assertValue(t, s, `{"a":[null,2],"b":null,"c":[null,2]}`)
+
+	s, _ = lept.Stringify(lept.MergePatch(doc, mustParse(t, `{"b": 1}`)))
+	assertValue(t, s, `{"a":[null,1],"b":1}`)
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{`{"a": 1}`, `{"a": 1.0}`, `[]`},
+		{`{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, `[]`},
+		{`{"a": 1}`, `{"a": 2}`, `[{"op":"replace","path":"/a","value":2}]`},
+		{`{"a": 1, "b": 2}`, `{"b": 2, "c/d": 3}`, `[{"op":"remove","path":"/a"},{"op":"add","path":"/c~1d","value":3}]`},
+		{`{"a": {"b": [1]}}`, `{"a": {"b": {"c": 1}}}`, `[{"op":"replace","path":"/a/b","value":{"c":1}}]`},
+		{`1`, `"x"`, `[{"op":"replace","path":"","value":"x"}]`},
+		{`[1, 2, 3]`, `[1, 3]`, `[{"op":"remove","path":"/1"}]`},
+		{`[1, 3]`, `[1, 2, 3]`, `[{"op":"add","path":"/1","value":2}]`},
+		{`[1, 2, 3]`, `[1, 2, 3, 4, 5]`, `[{"op":"add","path":"/3","value":4},{"op":"add","path":"/4","value":5}]`},
+		{`[0, 1, 2, 3]`, `[1, 3, 4]`, `[{"op":"remove","path":"/0"},{"op":"remove","path":"/1"},{"op":"add","path":"/2","value":4}]`},
+		{`[{"a": 1}, 5]`, `[{"a": 2}, 5]`, `[{"op":"replace","path":"/0/a","value":2}]`},
+		{`["a", "b", "c", "d"]`, `["x", "b", "y", "z", "d"]`, `[{"op":"replace","path":"/0","value":"x"},{"op":"replace","path":"/2","value":"y"},{"op":"add","path":"/3","value":"z"}]`},
+	}
+	for _, tt := range tests {
+		a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+		patch := lept.Diff(a, b)
+		s, _ := lept.Stringify(patch)
+		assertValue(t, s, tt.want)
+
+		got, err := lept.ApplyPatch(a, patch)
+		if err != nil {
+			t.Errorf("apply diff %s failed: %v", s, err)
+			continue
+		}
+		if len(lept.Diff(got, b).ARRAY()) != 0 {
+			t.Errorf("diff of %s and %s does not round trip", tt.a, tt.b)
+		}
+	}
+}
+
+func TestDiffRoundtrip(t *testing.T) {
+	a := mustParse(t, store)
+	b := mustParse(t, `{ "store": {
+		"book": [
+			{ "category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 13.99 },
+			{ "category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95 },
+			{ "category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99, "tags": ["epic"] }
+		],
+		"bicycle": { "color": "blue", "price": 399 },
+		"open": true
+	} }`)
+	got, err := lept.ApplyPatch(a, lept.Diff(a, b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, len(lept.Diff(got, b).ARRAY()), 0)
+	assertValue(t, got.Get("store").Get("book").ARRAY()[2].Get("isbn").STRING(), "0-395-19395-8")
+}
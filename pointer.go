@@ -0,0 +1,219 @@
+package lept
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrPointerSyntax = errors.New("invalid json pointer")
+var ErrPointerNotFound = errors.New("json pointer not found")
+
+// Pointer is a parsed RFC 6901 JSON Pointer, one unescaped reference token
+// per element. The empty Pointer refers to the whole document.
+type Pointer []string
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, errorf("%w %q: must start with '/'", ErrPointerSyntax, s)
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		for j := 0; j < len(t); j++ {
+			if t[j] == '~' && (j+1 == len(t) || t[j+1] != '0' && t[j+1] != '1') {
+				return nil, errorf("%w %q: bad escape", ErrPointerSyntax, s)
+			}
+		}
+		tokens[i] = pointerUnescaper.Replace(t)
+	}
+	return Pointer(tokens), nil
+}
+
+func (p Pointer) String() string {
+	sb := strings.Builder{}
+	for _, t := range p {
+		sb.WriteByte('/')
+		pointerEscaper.WriteString(&sb, t)
+	}
+	return sb.String()
+}
+
+func (p Pointer) Get(v *Value) (*Value, error) {
+	for i, t := range p {
+		next, err := child(v, t)
+		if err != nil {
+			return nil, errorf("%w: %s", err, p[:i+1])
+		}
+		v = next
+	}
+	return v, nil
+}
+
+// Set replaces the value p refers to, adding an object member when it does
+// not exist yet and appending to an array when the last token is "-" or the
+// array length.
+func (p Pointer) Set(v *Value, val *Value) error {
+	if len(p) == 0 {
+		*v = *val
+		return nil
+	}
+
+	parent, err := p[:len(p)-1].Get(v)
+	if err != nil {
+		return err
+	}
+	t := p[len(p)-1]
+	switch parent.Type {
+	case TypeObject:
+		obj := parent.U.(Object)
+		for i := len(obj) - 1; i >= 0; i-- {
+			if obj[i].K == t {
+				obj[i].V = val
+				return nil
+			}
+		}
+		parent.U = append(obj, Member{t, val})
+		return nil
+	case TypeArray:
+		arr := parent.U.(Array)
+		i, err := arrayIndex(t, len(arr))
+		if err == nil && i > len(arr) {
+			err = ErrPointerNotFound
+		}
+		if err != nil {
+			return errorf("%w: %s", err, p)
+		}
+		if i == len(arr) {
+			parent.U = append(arr, val)
+		} else {
+			arr[i] = val
+		}
+		return nil
+	default:
+		return errorf("%w: %s", ErrPointerNotFound, p)
+	}
+}
+
+// Insert adds val at p like the JSON Patch "add" operation: array elements
+// at and after the index are shifted instead of replaced.
+func (p Pointer) Insert(v *Value, val *Value) error {
+	if len(p) == 0 {
+		*v = *val
+		return nil
+	}
+
+	parent, err := p[:len(p)-1].Get(v)
+	if err != nil {
+		return err
+	}
+	if parent.Type != TypeArray {
+		return p.Set(v, val)
+	}
+	arr := parent.U.(Array)
+	i, err := arrayIndex(p[len(p)-1], len(arr))
+	if err == nil && i > len(arr) {
+		err = ErrPointerNotFound
+	}
+	if err != nil {
+		return errorf("%w: %s", err, p)
+	}
+	parent.U = slices.Insert(arr, i, val)
+	return nil
+}
+
+// Remove deletes the value p refers to and returns it. Every member with the
+// referenced key is removed from an object holding duplicates.
+func (p Pointer) Remove(v *Value) (*Value, error) {
+	if len(p) == 0 {
+		return nil, errorf("%w: cannot remove the document root", ErrPointerNotFound)
+	}
+
+	parent, err := p[:len(p)-1].Get(v)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := child(parent, p[len(p)-1])
+	if err != nil {
+		return nil, errorf("%w: %s", err, p)
+	}
+
+	switch parent.Type {
+	case TypeObject:
+		t := p[len(p)-1]
+		parent.U = slices.DeleteFunc(parent.U.(Object), func(m Member) bool {
+			return m.K == t
+		})
+	case TypeArray:
+		i, _ := arrayIndex(p[len(p)-1], len(parent.ARRAY()))
+		parent.U = slices.Delete(parent.U.(Array), i, i+1)
+	}
+	return removed, nil
+}
+
+func child(v *Value, t string) (*Value, error) {
+	switch v.Type {
+	case TypeObject:
+		if e := v.OBJECT().Get(t); e != nil {
+			return e, nil
+		}
+	case TypeArray:
+		arr := v.ARRAY()
+		i, err := arrayIndex(t, len(arr))
+		if err != nil {
+			return nil, err
+		}
+		if i < len(arr) {
+			return arr[i], nil
+		}
+	}
+	return nil, ErrPointerNotFound
+}
+
+// arrayIndex parses an array reference token for an array of length n, where
+// "-" stands for the position past the last element. Bounds are left to the
+// caller.
+func arrayIndex(t string, n int) (int, error) {
+	if t == "-" {
+		return n, nil
+	}
+	if t == "" || len(t) > 1 && t[0] == '0' || strings.Trim(t, "0123456789") != "" {
+		return 0, ErrPointerSyntax
+	}
+	i, err := strconv.Atoi(t)
+	if err != nil {
+		return 0, ErrPointerNotFound
+	}
+	return i, nil
+}
+
+func (v *Value) Pointer(ptr string) (*Value, error) {
+	p, err := ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Get(v)
+}
+
+func (v *Value) SetPointer(ptr string, val *Value) error {
+	p, err := ParsePointer(ptr)
+	if err != nil {
+		return err
+	}
+	return p.Set(v, val)
+}
+
+func (v *Value) RemovePointer(ptr string) (*Value, error) {
+	p, err := ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Remove(v)
+}
@@ -0,0 +1,167 @@
+package lept_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+const rfc6901 = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8
+}`
+
+func TestPointer(t *testing.T) {
+	v, err := lept.Parse(rfc6901)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ptr  string
+		want string
+	}{
+		{"", `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}`},
+		{"/foo", `["bar","baz"]`},
+		{"/foo/0", `"bar"`},
+		{"/foo/1", `"baz"`},
+		{"/", `0`},
+		{"/a~1b", `1`},
+		{"/c%d", `2`},
+		{"/e^f", `3`},
+		{"/g|h", `4`},
+		{"/i\\j", `5`},
+		{"/k\"l", `6`},
+		{"/ ", `7`},
+		{"/m~0n", `8`},
+	}
+	for _, tt := range tests {
+		e, err := v.Pointer(tt.ptr)
+		if err != nil {
+			t.Errorf("pointer %q failed: %v", tt.ptr, err)
+			continue
+		}
+		got, _ := lept.Stringify(e)
+		assertValue(t, got, tt.want)
+
+		p, err := lept.ParsePointer(tt.ptr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertValue(t, p.String(), tt.ptr)
+	}
+
+	errs := []struct {
+		ptr  string
+		want error
+	}{
+		{"foo", lept.ErrPointerSyntax},
+		{"/m~2n", lept.ErrPointerSyntax},
+		{"/m~", lept.ErrPointerSyntax},
+		{"/foo/01", lept.ErrPointerSyntax},
+		{"/foo/-1", lept.ErrPointerSyntax},
+		{"/foo/2", lept.ErrPointerNotFound},
+		{"/foo/-", lept.ErrPointerNotFound},
+		{"/foo/0/x", lept.ErrPointerNotFound},
+		{"/missing", lept.ErrPointerNotFound},
+		{"/foo/99999999999999999999999", lept.ErrPointerNotFound},
+	}
+	for _, tt := range errs {
+		if _, err := v.Pointer(tt.ptr); !errors.Is(err, tt.want) {
+			t.Errorf("pointer %q: got error %v want %v", tt.ptr, err, tt.want)
+		}
+	}
+}
+
+func TestPointerString(t *testing.T) {
+	p := lept.Pointer{"a/b", "m~n", "0", ""}
+	assertValue(t, p.String(), "/a~1b/m~0n/0/")
+	q, err := lept.ParsePointer(p.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, len(q), 4)
+	assertValue(t, q[1], "m~n")
+	assertValue(t, lept.Pointer{}.String(), "")
+
+	// "~01" unescapes to "~1", not "/"
+	q, err = lept.ParsePointer("/~01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, q[0], "~1")
+}
+
+func TestSetPointer(t *testing.T) {
+	v, err := lept.Parse(`{"author": ["Erich Gamma", "Richard Helm"], "publisher": {"Company": "Pearson"}, "dup": 1, "dup": 2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		ptr string
+		val *lept.Value
+	}{
+		{"/author/1", lept.NewString("Ralph Johnson")},
+		{"/author/-", lept.NewString("John Vlissides")},
+		{"/author/3", lept.NewString("Grady Booch")},
+		{"/publisher/Country", lept.NewString("India")},
+		{"/publisher/Company", lept.NewString("Pearson Education")},
+		{"/dup", lept.NewNumber(3)},
+		{"/year", lept.NewNumber(2009)},
+	}
+	for _, s := range steps {
+		if err := v.SetPointer(s.ptr, s.val); err != nil {
+			t.Fatalf("set %q failed: %v", s.ptr, err)
+		}
+	}
+	got, _ := lept.Stringify(v)
+	assertValue(t, got, `{"author":["Erich Gamma","Ralph Johnson","John Vlissides","Grady Booch"],"publisher":{"Company":"Pearson Education","Country":"India"},"dup":1,"dup":3,"year":2009}`)
+
+	for _, ptr := range []string{"/author/5", "/missing/x", "/year/0", "/author/x"} {
+		if err := v.SetPointer(ptr, lept.NewNull()); err == nil {
+			t.Errorf("set %q: expected error", ptr)
+		}
+	}
+
+	if err := v.SetPointer("", lept.NewArray()); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, v.Type, lept.TypeArray)
+}
+
+func TestRemovePointer(t *testing.T) {
+	v, err := lept.Parse(`{"author": ["Erich Gamma", "Richard Helm", "Ralph Johnson"], "dup": 1, "dup": 2, "year": 2009}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := v.RemovePointer("/author/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, removed.STRING(), "Richard Helm")
+	removed, err = v.RemovePointer("/dup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, removed.NUMBER(), 2.0)
+
+	got, _ := lept.Stringify(v)
+	assertValue(t, got, `{"author":["Erich Gamma","Ralph Johnson"],"year":2009}`)
+
+	for _, ptr := range []string{"", "/dup", "/author/2", "/author/-", "/year/0"} {
+		if _, err := v.RemovePointer(ptr); err == nil {
+			t.Errorf("remove %q: expected error", ptr)
+		}
+	}
+}
@@ -0,0 +1,801 @@
+package lept
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrInvalidSchema = errors.New("invalid json schema")
+var ErrValidation = errors.New("json schema validation failed")
+
+// maxRefDepth bounds how deeply $ref may recurse during validation, so that
+// a schema referring to itself without descending cannot loop forever.
+const maxRefDepth = 1000
+
+// Schema is a compiled JSON Schema (draft 2020-12). It supports the type,
+// enum, const, numeric, string, array and object assertions, the allOf,
+// anyOf, oneOf, not and if/then/else applicators, and $ref to $defs, JSON
+// Pointers and $anchor names within the same document. Formats are asserted
+// for the common formats and ignored otherwise. A Schema is safe for
+// concurrent use.
+type Schema struct {
+	root *schema
+}
+
+// SchemaError is a single failed assertion.
+type SchemaError struct {
+	InstanceLocation Pointer // location of the failing value in the instance
+	KeywordLocation  Pointer // location of the failing keyword in the schema
+	Message          string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%q: %s (%s)", e.InstanceLocation.String(), e.Message, e.KeywordLocation)
+}
+
+// ValidationError lists every assertion a value failed. It matches
+// ErrValidation with errors.Is.
+type ValidationError struct {
+	Errors []*SchemaError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return ErrValidation.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func CompileSchema(v *Value) (*Schema, error) {
+	c := &schemaCompiler{doc: v, schemas: map[string]*schema{}, anchors: map[string]Pointer{}}
+	if id := v.Get("$id"); id != nil {
+		c.id, _, _ = strings.Cut(id.STRING(), "#")
+	}
+	c.findAnchors(v, Pointer{})
+	root, err := c.compile(v, Pointer{})
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root}, nil
+}
+
+// Validate checks v against the schema, returning a *ValidationError with
+// all failures or nil.
+func (s *Schema) Validate(v *Value) error {
+	st := &validation{}
+	s.root.validate(st, v, Pointer{})
+	if len(st.errs) > 0 {
+		return &ValidationError{st.errs}
+	}
+	return nil
+}
+
+type schema struct {
+	checks []schemaCheck
+}
+
+type schemaCheck func(st *validation, v *Value, inst Pointer)
+
+type validation struct {
+	errs  []*SchemaError
+	depth int
+}
+
+func (st *validation) fail(inst, kw Pointer, format string, args ...any) {
+	st.errs = append(st.errs, &SchemaError{inst, kw, fmt.Sprintf(format, args...)})
+}
+
+func (s *schema) validate(st *validation, v *Value, inst Pointer) {
+	for _, check := range s.checks {
+		check(st, v, inst)
+	}
+}
+
+// matches reports whether v is valid without recording any failures.
+func (s *schema) matches(st *validation, v *Value, inst Pointer) bool {
+	sub := &validation{depth: st.depth}
+	s.validate(sub, v, inst)
+	return len(sub.errs) == 0
+}
+
+type schemaCompiler struct {
+	doc     *Value
+	id      string
+	schemas map[string]*schema // by location, so shared and recursive refs compile once
+	anchors map[string]Pointer
+}
+
+func schemaErrorf(loc Pointer, format string, args ...any) error {
+	return errorf("%w at %q: "+format, append([]any{ErrInvalidSchema, loc.String()}, args...)...)
+}
+
+func (c *schemaCompiler) findAnchors(v *Value, loc Pointer) {
+	switch v.Type {
+	case TypeObject:
+		if a := v.Get("$anchor"); a != nil && a.Type == TypeString {
+			c.anchors[a.STRING()] = loc
+		}
+		for _, m := range v.OBJECT() {
+			c.findAnchors(m.V, loc.child(m.K))
+		}
+	case TypeArray:
+		for i, e := range v.ARRAY() {
+			c.findAnchors(e, loc.child(strconv.Itoa(i)))
+		}
+	}
+}
+
+func (c *schemaCompiler) compile(v *Value, loc Pointer) (*schema, error) {
+	key := loc.String()
+	if s, ok := c.schemas[key]; ok {
+		return s, nil
+	}
+	s := &schema{}
+	c.schemas[key] = s
+
+	switch v.Type {
+	case TypeTrue:
+		return s, nil
+	case TypeFalse:
+		s.checks = append(s.checks, func(st *validation, v *Value, inst Pointer) {
+			st.fail(inst, loc, "no value is allowed")
+		})
+		return s, nil
+	case TypeObject:
+	default:
+		return nil, schemaErrorf(loc, "schema must be an object or a boolean")
+	}
+
+	for _, m := range v.OBJECT() {
+		check, err := c.keyword(v, m.K, m.V, loc.child(m.K))
+		if err != nil {
+			return nil, err
+		}
+		if check != nil {
+			s.checks = append(s.checks, check)
+		}
+	}
+	return s, nil
+}
+
+// keyword compiles the keyword k with value kv of the schema object parent.
+// Unknown keywords and those handled by a sibling compile to nil.
+func (c *schemaCompiler) keyword(parent *Value, k string, kv *Value, loc Pointer) (schemaCheck, error) {
+	switch k {
+	case "type":
+		return c.typeKeyword(kv, loc)
+	case "enum":
+		if kv.Type != TypeArray {
+			return nil, schemaErrorf(loc, "enum must be an array")
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			for _, e := range kv.ARRAY() {
+				if Equal(v, e) {
+					return
+				}
+			}
+			st.fail(inst, loc, "must be one of %s", jsonText(kv))
+		}, nil
+	case "const":
+		return func(st *validation, v *Value, inst Pointer) {
+			if !Equal(v, kv) {
+				st.fail(inst, loc, "must be %s", jsonText(kv))
+			}
+		}, nil
+
+	case "multipleOf":
+		if kv.Type != TypeNumber || Compare(kv, NewNumber(0)) <= 0 {
+			return nil, schemaErrorf(loc, "multipleOf must be a number greater than 0")
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if v.Type == TypeNumber && !isMultiple(v, kv) {
+				st.fail(inst, loc, "must be a multiple of %s", jsonText(kv))
+			}
+		}, nil
+	case "minimum", "exclusiveMinimum", "maximum", "exclusiveMaximum":
+		return numberBound(k, kv, loc)
+
+	case "minLength", "maxLength":
+		n, err := schemaCount(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if v.Type != TypeString {
+				return
+			}
+			l := utf8.RuneCountInString(v.STRING())
+			if k == "minLength" && l < n {
+				st.fail(inst, loc, "must be at least %d characters long", n)
+			} else if k == "maxLength" && l > n {
+				st.fail(inst, loc, "must be at most %d characters long", n)
+			}
+		}, nil
+	case "pattern":
+		re, err := schemaRegexp(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if v.Type == TypeString && !re.MatchString(v.STRING()) {
+				st.fail(inst, loc, "must match pattern %q", re)
+			}
+		}, nil
+	case "format":
+		if kv.Type != TypeString {
+			return nil, schemaErrorf(loc, "format must be a string")
+		}
+		valid, ok := schemaFormats[kv.STRING()]
+		if !ok {
+			return nil, nil
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if v.Type == TypeString && !valid(v.STRING()) {
+				st.fail(inst, loc, "must be a valid %s", kv.STRING())
+			}
+		}, nil
+
+	case "minItems", "maxItems":
+		n, err := schemaCount(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if v.Type != TypeArray {
+				return
+			}
+			l := len(v.ARRAY())
+			if k == "minItems" && l < n {
+				st.fail(inst, loc, "must have at least %d items", n)
+			} else if k == "maxItems" && l > n {
+				st.fail(inst, loc, "must have at most %d items", n)
+			}
+		}, nil
+	case "uniqueItems":
+		if kv.Type != TypeTrue && kv.Type != TypeFalse {
+			return nil, schemaErrorf(loc, "uniqueItems must be a boolean")
+		}
+		if !kv.BOOL() {
+			return nil, nil
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if v.Type != TypeArray {
+				return
+			}
+			seen := map[uint64][]int{}
+			arr := v.ARRAY()
+			for i, e := range arr {
+				h := Hash(e)
+				for _, j := range seen[h] {
+					if Equal(arr[j], e) {
+						st.fail(inst, loc, "items %d and %d must be unique", j, i)
+						return
+					}
+				}
+				seen[h] = append(seen[h], i)
+			}
+		}, nil
+	case "prefixItems":
+		prefix, err := c.schemaList(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			for i, e := range v.ARRAY() {
+				if i < len(prefix) {
+					prefix[i].validate(st, e, inst.child(strconv.Itoa(i)))
+				}
+			}
+		}, nil
+	case "items":
+		items, err := c.compile(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		start := 0
+		if p := parent.Get("prefixItems"); p != nil {
+			start = len(p.ARRAY())
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			for i, e := range v.ARRAY() {
+				if i >= start {
+					items.validate(st, e, inst.child(strconv.Itoa(i)))
+				}
+			}
+		}, nil
+
+	case "minProperties", "maxProperties":
+		n, err := schemaCount(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if v.Type != TypeObject {
+				return
+			}
+			l := len(lastMembers(v.OBJECT()))
+			if k == "minProperties" && l < n {
+				st.fail(inst, loc, "must have at least %d properties", n)
+			} else if k == "maxProperties" && l > n {
+				st.fail(inst, loc, "must have at most %d properties", n)
+			}
+		}, nil
+	case "required":
+		names, err := schemaStrings(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if v.Type != TypeObject {
+				return
+			}
+			for _, name := range names {
+				if v.Get(name) == nil {
+					st.fail(inst, loc, "missing required property %q", name)
+				}
+			}
+		}, nil
+	case "properties":
+		props, err := c.schemaMap(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			for _, m := range v.OBJECT() {
+				if s, ok := props[m.K]; ok {
+					s.validate(st, m.V, inst.child(m.K))
+				}
+			}
+		}, nil
+	case "patternProperties":
+		patterns, err := c.patternSchemas(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			for _, m := range v.OBJECT() {
+				for _, p := range patterns {
+					if p.re.MatchString(m.K) {
+						p.s.validate(st, m.V, inst.child(m.K))
+					}
+				}
+			}
+		}, nil
+	case "additionalProperties":
+		return c.additionalProperties(parent, kv, loc)
+	case "propertyNames":
+		names, err := c.compile(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			for _, m := range v.OBJECT() {
+				names.validate(st, NewString(m.K), inst.child(m.K))
+			}
+		}, nil
+
+	case "allOf", "anyOf", "oneOf":
+		list, err := c.schemaList(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) == 0 {
+			return nil, schemaErrorf(loc, "%s must not be empty", k)
+		}
+		if k == "allOf" {
+			return func(st *validation, v *Value, inst Pointer) {
+				for _, s := range list {
+					s.validate(st, v, inst)
+				}
+			}, nil
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			n := 0
+			for _, s := range list {
+				if s.matches(st, v, inst) {
+					n++
+				}
+			}
+			if k == "anyOf" && n == 0 {
+				st.fail(inst, loc, "must match at least one schema")
+			} else if k == "oneOf" && n != 1 {
+				st.fail(inst, loc, "must match exactly one schema, matched %d", n)
+			}
+		}, nil
+	case "not":
+		not, err := c.compile(kv, loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if not.matches(st, v, inst) {
+				st.fail(inst, loc, "must not match the schema")
+			}
+		}, nil
+	case "if":
+		return c.conditional(parent, kv, loc)
+
+	case "$ref":
+		if kv.Type != TypeString {
+			return nil, schemaErrorf(loc, "$ref must be a string")
+		}
+		target, err := c.ref(kv.STRING(), loc)
+		if err != nil {
+			return nil, err
+		}
+		return func(st *validation, v *Value, inst Pointer) {
+			if st.depth >= maxRefDepth {
+				st.fail(inst, loc, "$ref recursion is too deep")
+				return
+			}
+			st.depth++
+			target.validate(st, v, inst)
+			st.depth--
+		}, nil
+	case "$defs":
+		_, err := c.schemaMap(kv, loc)
+		return nil, err
+	}
+	return nil, nil
+}
+
+func numberBound(k string, kv *Value, loc Pointer) (schemaCheck, error) {
+	if kv.Type != TypeNumber {
+		return nil, schemaErrorf(loc, "%s must be a number", k)
+	}
+	op, ok := "", func(c int) bool { return c >= 0 }
+	switch k {
+	case "minimum":
+		op = ">="
+	case "exclusiveMinimum":
+		op, ok = ">", func(c int) bool { return c > 0 }
+	case "maximum":
+		op, ok = "<=", func(c int) bool { return c <= 0 }
+	case "exclusiveMaximum":
+		op, ok = "<", func(c int) bool { return c < 0 }
+	}
+
+	return func(st *validation, v *Value, inst Pointer) {
+		if v.Type == TypeNumber && !ok(Compare(v, kv)) {
+			st.fail(inst, loc, "must be %s %s", op, jsonText(kv))
+		}
+	}, nil
+}
+
+var schemaTypes = []string{"null", "boolean", "object", "array", "number", "string", "integer"}
+
+func (c *schemaCompiler) typeKeyword(kv *Value, loc Pointer) (schemaCheck, error) {
+	var types []string
+	if kv.Type == TypeString {
+		types = []string{kv.STRING()}
+	} else if types, _ = schemaStrings(kv, loc); types == nil {
+		return nil, schemaErrorf(loc, "type must be a string or an array of strings")
+	} else if len(types) == 0 {
+		return nil, schemaErrorf(loc, "type must not be an empty array")
+	}
+	for _, t := range types {
+		if !slices.Contains(schemaTypes, t) {
+			return nil, schemaErrorf(loc, "unknown type %q", t)
+		}
+	}
+
+	return func(st *validation, v *Value, inst Pointer) {
+		for _, t := range types {
+			if hasSchemaType(v, t) {
+				return
+			}
+		}
+		st.fail(inst, loc, "expected %s, got %s", strings.Join(types, " or "), schemaType(v))
+	}, nil
+}
+
+func (c *schemaCompiler) additionalProperties(parent, kv *Value, loc Pointer) (schemaCheck, error) {
+	additional, err := c.compile(kv, loc)
+	if err != nil {
+		return nil, err
+	}
+	var props map[string]*schema
+	if p := parent.Get("properties"); p != nil {
+		if props, err = c.schemaMap(p, loc[:len(loc)-1].child("properties")); err != nil {
+			return nil, err
+		}
+	}
+	var patterns []patternSchema
+	if p := parent.Get("patternProperties"); p != nil {
+		if patterns, err = c.patternSchemas(p, loc[:len(loc)-1].child("patternProperties")); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(st *validation, v *Value, inst Pointer) {
+	members:
+		for _, m := range v.OBJECT() {
+			if _, ok := props[m.K]; ok {
+				continue
+			}
+			for _, p := range patterns {
+				if p.re.MatchString(m.K) {
+					continue members
+				}
+			}
+			if kv.Type == TypeFalse {
+				st.fail(inst.child(m.K), loc, "additional property %q is not allowed", m.K)
+			} else {
+				additional.validate(st, m.V, inst.child(m.K))
+			}
+		}
+	}, nil
+}
+
+func (c *schemaCompiler) conditional(parent, kv *Value, loc Pointer) (schemaCheck, error) {
+	cond, err := c.compile(kv, loc)
+	if err != nil {
+		return nil, err
+	}
+	branch := func(k string) (*schema, error) {
+		if b := parent.Get(k); b != nil {
+			return c.compile(b, loc[:len(loc)-1].child(k))
+		}
+		return nil, nil
+	}
+	then, err := branch("then")
+	if err != nil {
+		return nil, err
+	}
+	els, err := branch("else")
+	if err != nil {
+		return nil, err
+	}
+
+	return func(st *validation, v *Value, inst Pointer) {
+		s := els
+		if cond.matches(st, v, inst) {
+			s = then
+		}
+		if s != nil {
+			s.validate(st, v, inst)
+		}
+	}, nil
+}
+
+func (c *schemaCompiler) ref(ref string, loc Pointer) (*schema, error) {
+	base, frag, _ := strings.Cut(ref, "#")
+	if base != "" && base != c.id {
+		return nil, schemaErrorf(loc, "unsupported remote reference %q", ref)
+	}
+	frag, err := url.PathUnescape(frag)
+	if err != nil {
+		return nil, schemaErrorf(loc, "invalid reference %q", ref)
+	}
+
+	var p Pointer
+	if frag == "" || frag[0] == '/' {
+		if p, err = ParsePointer(frag); err != nil {
+			return nil, schemaErrorf(loc, "invalid reference %q", ref)
+		}
+	} else if p = c.anchors[frag]; p == nil {
+		return nil, schemaErrorf(loc, "unknown anchor in reference %q", ref)
+	}
+	target, err := p.Get(c.doc)
+	if err != nil {
+		return nil, schemaErrorf(loc, "unresolved reference %q", ref)
+	}
+	return c.compile(target, p)
+}
+
+func (c *schemaCompiler) schemaList(kv *Value, loc Pointer) ([]*schema, error) {
+	if kv.Type != TypeArray {
+		return nil, schemaErrorf(loc, "must be an array of schemas")
+	}
+	list := make([]*schema, len(kv.ARRAY()))
+	for i, e := range kv.ARRAY() {
+		s, err := c.compile(e, loc.child(strconv.Itoa(i)))
+		if err != nil {
+			return nil, err
+		}
+		list[i] = s
+	}
+	return list, nil
+}
+
+func (c *schemaCompiler) schemaMap(kv *Value, loc Pointer) (map[string]*schema, error) {
+	if kv.Type != TypeObject {
+		return nil, schemaErrorf(loc, "must be an object of schemas")
+	}
+	m := map[string]*schema{}
+	for _, e := range kv.OBJECT() {
+		s, err := c.compile(e.V, loc.child(e.K))
+		if err != nil {
+			return nil, err
+		}
+		m[e.K] = s
+	}
+	return m, nil
+}
+
+type patternSchema struct {
+	re *regexp.Regexp
+	s  *schema
+}
+
+func (c *schemaCompiler) patternSchemas(kv *Value, loc Pointer) ([]patternSchema, error) {
+	if kv.Type != TypeObject {
+		return nil, schemaErrorf(loc, "must be an object of schemas")
+	}
+	var patterns []patternSchema
+	for _, e := range kv.OBJECT() {
+		re, err := schemaRegexp(NewString(e.K), loc.child(e.K))
+		if err != nil {
+			return nil, err
+		}
+		s, err := c.compile(e.V, loc.child(e.K))
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, patternSchema{re, s})
+	}
+	return patterns, nil
+}
+
+func schemaCount(kv *Value, loc Pointer) (int, error) {
+	n, err := kv.Int64()
+	if err != nil || n < 0 {
+		return 0, schemaErrorf(loc, "must be a non-negative integer")
+	}
+	return int(min(n, math.MaxInt32)), nil
+}
+
+func schemaStrings(kv *Value, loc Pointer) ([]string, error) {
+	if kv.Type != TypeArray {
+		return nil, schemaErrorf(loc, "must be an array of strings")
+	}
+	ss := []string{}
+	for _, e := range kv.ARRAY() {
+		if e.Type != TypeString {
+			return nil, schemaErrorf(loc, "must be an array of strings")
+		}
+		ss = append(ss, e.STRING())
+	}
+	return ss, nil
+}
+
+func schemaRegexp(kv *Value, loc Pointer) (*regexp.Regexp, error) {
+	if kv.Type != TypeString {
+		return nil, schemaErrorf(loc, "pattern must be a string")
+	}
+	re, err := regexp.Compile(kv.STRING())
+	if err != nil {
+		return nil, schemaErrorf(loc, "invalid pattern: %v", err)
+	}
+	return re, nil
+}
+
+func schemaType(v *Value) string {
+	switch v.Type {
+	case TypeNull:
+		return "null"
+	case TypeTrue, TypeFalse:
+		return "boolean"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeArray:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func hasSchemaType(v *Value, t string) bool {
+	if t == "integer" {
+		d, ok := decimalOf(v)
+		return v.Type == TypeNumber && ok && (d.exp >= 0 || d.mant == "0")
+	}
+	return t == schemaType(v) || t == "number" && v.Type == TypeNumber
+}
+
+// isMultiple reports whether v is an integer multiple of m, exactly when
+// the exponents are small enough to expand.
+func isMultiple(v, m *Value) bool {
+	dv, okv := decimalOf(v)
+	dm, okm := decimalOf(m)
+	if !okv || !okm || absInt(dv.exp) > maxBigDigits || absInt(dm.exp) > maxBigDigits {
+		q := v.NUMBER() / m.NUMBER()
+		return !math.IsInf(q, 0) && q == math.Trunc(q)
+	}
+	nv, _ := v.number()
+	nm, _ := m.number()
+	rv, _ := new(big.Rat).SetString(string(nv))
+	rm, _ := new(big.Rat).SetString(string(nm))
+	return new(big.Rat).Quo(rv, rm).IsInt()
+}
+
+func absInt(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+func jsonText(v *Value) string {
+	s, _ := Stringify(v)
+	return s
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var schemaFormats = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(s))
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	},
+	"time": func(s string) bool {
+		_, err := time.Parse("15:04:05.999999999Z07:00", strings.ToUpper(s))
+		return err == nil
+	},
+	"email": func(s string) bool {
+		a, err := mail.ParseAddress(s)
+		return err == nil && a.Address == s
+	},
+	"hostname": isHostname,
+	"ipv4": func(s string) bool {
+		a, err := netip.ParseAddr(s)
+		return err == nil && a.Is4()
+	},
+	"ipv6": func(s string) bool {
+		a, err := netip.ParseAddr(s)
+		return err == nil && a.Is6() && a.Zone() == ""
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	},
+	"uri-reference": func(s string) bool {
+		_, err := url.Parse(s)
+		return err == nil
+	},
+	"uuid": uuidPattern.MatchString,
+	"regex": func(s string) bool {
+		_, err := regexp.Compile(s)
+		return err == nil
+	},
+}
+
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package lept_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func mustCompileSchema(t *testing.T, schema string) *lept.Schema {
+	t.Helper()
+	s, err := lept.CompileSchema(mustParse(t, schema))
+	if err != nil {
+		t.Fatalf("compile %s failed: %v", schema, err)
+	}
+	return s
+}
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		schema  string
+		valid   []string
+		invalid []string
+	}{
+		{`true`, []string{`1`, `{}`}, nil},
+		{`false`, nil, []string{`1`, `null`}},
+		{`{}`, []string{`1`, `"a"`, `[{}]`}, nil},
+		{`{"type": "integer"}`, []string{`1`, `1.0`, `-3e2`, `0`}, []string{`1.5`, `"1"`, `null`}},
+		{`{"type": ["string", "null"]}`, []string{`"a"`, `null`}, []string{`1`, `[]`}},
+		{`{"type": "number"}`, []string{`1`, `1.5`}, []string{`true`}},
+		{`{"enum": [1, "a", {"b": [null]}]}`, []string{`1.0`, `"a"`, `{"b": [null]}`}, []string{`2`, `"b"`, `{"b": []}`}},
+		{`{"const": {"a": 1, "b": 2}}`, []string{`{"b": 2, "a": 1}`}, []string{`{"a": 1}`}},
+		{`{"minimum": 1, "exclusiveMaximum": 10}`, []string{`1`, `9.999`, `"x"`}, []string{`0.99`, `10`, `12345678901234567890`}},
+		{`{"exclusiveMinimum": 0, "maximum": 1e2}`, []string{`0.1`, `100`}, []string{`0`, `100.01`}},
+		{`{"multipleOf": 0.01}`, []string{`1.23`, `100`, `0`}, []string{`1.234`}},
+		{`{"multipleOf": 3}`, []string{`9`, `-3`, `12345678901234567893`}, []string{`10`, `12345678901234567891`}},
+		{`{"minLength": 2, "maxLength": 3}`, []string{`"ab"`, `"日本語"`, `1`}, []string{`"a"`, `"abcd"`}},
+		{`{"pattern": "^[a-z]+\\d$"}`, []string{`"abc1"`, `2`}, []string{`"abc"`, `"Abc1"`}},
+		{`{"pattern": "b"}`, []string{`"abc"`}, []string{`"ac"`}},
+		{`{"minItems": 1, "maxItems": 2, "uniqueItems": true}`, []string{`[1]`, `[1, "1"]`, `{}`}, []string{`[]`, `[1, 2, 3]`, `[1, 1.0]`, `[{"a": 1, "b": 2}, {"b": 2, "a": 1}]`}},
+		{`{"prefixItems": [{"type": "string"}, {"type": "number"}], "items": false}`, []string{`["a", 1]`, `["a"]`, `[]`}, []string{`[1, "a"]`, `["a", 1, null]`}},
+		{`{"items": {"type": "boolean"}}`, []string{`[true, false]`, `[]`}, []string{`[true, 0]`}},
+		{`{"minProperties": 1, "maxProperties": 2}`, []string{`{"a": 1}`, `{"a": 1, "a": 2}`}, []string{`{}`, `{"a": 1, "b": 2, "c": 3}`}},
+		{`{"required": ["a", "b"]}`, []string{`{"a": 1, "b": null}`, `[]`}, []string{`{"a": 1}`, `{}`}},
+		{`{"properties": {"a": {"type": "string"}}, "patternProperties": {"^x-": {"type": "integer"}}, "additionalProperties": false}`,
+			[]string{`{"a": "s", "x-1": 1}`, `{}`}, []string{`{"a": 1}`, `{"x-1": "s"}`, `{"b": 1}`}},
+		{`{"additionalProperties": {"type": "number"}, "properties": {"a": {}}}`, []string{`{"a": "s", "b": 1}`}, []string{`{"b": "s"}`}},
+		{`{"propertyNames": {"maxLength": 3}}`, []string{`{"abc": 1}`}, []string{`{"abcd": 1}`}},
+		{`{"allOf": [{"type": "number"}, {"minimum": 2}]}`, []string{`2`}, []string{`1`, `"a"`}},
+		{`{"anyOf": [{"type": "string"}, {"minimum": 2}]}`, []string{`"a"`, `3`}, []string{`1`}},
+		{`{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, []string{`1`, `2.5`}, []string{`3`, `1.5`}},
+		{`{"not": {"type": "null"}}`, []string{`1`}, []string{`null`}},
+		{`{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"type": "number"}}`, []string{`"ab"`, `1`}, []string{`"a"`, `null`}},
+		{`{"if": {"minimum": 5}, "then": {"multipleOf": 5}}`, []string{`10`, `3`}, []string{`7`}},
+		{`{"$defs": {"pos": {"type": "integer", "minimum": 1}}, "properties": {"n": {"$ref": "#/$defs/pos"}}}`, []string{`{"n": 1}`}, []string{`{"n": 0}`, `{"n": "1"}`}},
+		{`{"$defs": {"a~b": {"const": 1}, "c d": {"const": 2}}, "anyOf": [{"$ref": "#/$defs/a~0b"}, {"$ref": "#/$defs/c%20d"}]}`, []string{`1`, `2`}, []string{`3`}},
+		{`{"$defs": {"x": {"$anchor": "item", "type": "string"}}, "items": {"$ref": "#item"}}`, []string{`["a"]`}, []string{`[1]`}},
+		{`{"$id": "https://example.com/tree", "type": "object", "properties": {"value": {"type": "number"}, "children": {"type": "array", "items": {"$ref": "https://example.com/tree"}}}}`,
+			[]string{`{"value": 1, "children": [{"value": 2, "children": []}]}`}, []string{`{"value": 1, "children": [{"value": "2"}]}`}},
+		{`{"$ref": "#/$defs/a", "$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"type": "null"}}}`, []string{`null`}, []string{`0`}},
+		{`{"format": "date-time"}`, []string{`"2024-02-29T12:30:00Z"`, `"1985-04-12t23:20:50.52+01:00"`, `1`}, []string{`"2023-02-29T12:30:00Z"`, `"2024-01-01"`}},
+		{`{"format": "date"}`, []string{`"2024-01-31"`}, []string{`"2024-1-31"`, `"2024-02-30"`}},
+		{`{"format": "time"}`, []string{`"08:30:06Z"`, `"08:30:06.5+02:00"`}, []string{`"8:30"`}},
+		{`{"format": "email"}`, []string{`"joe@example.com"`}, []string{`"joe"`, `"Joe <joe@example.com>"`}},
+		{`{"format": "hostname"}`, []string{`"example.com"`, `"a-b.c"`}, []string{`"-a.com"`, `"a..b"`, `"a_b"`}},
+		{`{"format": "ipv4"}`, []string{`"192.168.0.1"`}, []string{`"192.168.0.01"`, `"::1"`, `"256.0.0.1"`}},
+		{`{"format": "ipv6"}`, []string{`"::1"`, `"2001:db8::8a2e:370:7334"`}, []string{`"192.168.0.1"`, `"::1%eth0"`}},
+		{`{"format": "uri"}`, []string{`"https://example.com/a?b#c"`}, []string{`"/relative"`}},
+		{`{"format": "uuid"}`, []string{`"123e4567-e89b-12d3-a456-426614174000"`}, []string{`"123e4567e89b12d3a456426614174000"`}},
+		{`{"format": "regex"}`, []string{`"^a+$"`}, []string{`"(a"`}},
+		{`{"format": "x-unknown"}`, []string{`"anything"`}, nil},
+		{`{"x-extension": 1, "description": "unknown keywords are ignored"}`, []string{`1`}, nil},
+	}
+	for _, tt := range tests {
+		s := mustCompileSchema(t, tt.schema)
+		for _, v := range tt.valid {
+			if err := s.Validate(mustParse(t, v)); err != nil {
+				t.Errorf("schema %s: %s should be valid: %v", tt.schema, v, err)
+			}
+		}
+		for _, v := range tt.invalid {
+			if err := s.Validate(mustParse(t, v)); !errors.Is(err, lept.ErrValidation) {
+				t.Errorf("schema %s: %s should be invalid, got %v", tt.schema, v, err)
+			}
+		}
+	}
+}
+
+func TestSchemaErrorLocation(t *testing.T) {
+	s := mustCompileSchema(t, `{
+		"type": "object",
+		"required": ["title", "author"],
+		"properties": {
+			"author": {"type": "array", "items": {"$ref": "#/$defs/name"}},
+			"year": {"type": "integer", "minimum": 1450},
+			"publisher": {"additionalProperties": false, "properties": {"Company": {}}}
+		},
+		"$defs": {"name": {"type": "string", "minLength": 1}}
+	}`)
+	err := s.Validate(mustParse(t, `{
+		"author": ["Erich Gamma", "", 3],
+		"year": 1400.5,
+		"publisher": {"Company": "Pearson Education", "Country": "India"}
+	}`))
+
+	var ve *lept.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	want := []struct {
+		inst, kw string
+	}{
+		{"", "/required"},
+		{"/author/1", "/$defs/name/minLength"},
+		{"/author/2", "/$defs/name/type"},
+		{"/year", "/properties/year/type"},
+		{"/year", "/properties/year/minimum"},
+		{"/publisher/Country", "/properties/publisher/additionalProperties"},
+	}
+	if len(ve.Errors) != len(want) {
+		t.Fatalf("got %d errors want %d: %v", len(ve.Errors), len(want), err)
+	}
+	for i, w := range want {
+		assertValue(t, ve.Errors[i].InstanceLocation.String(), w.inst)
+		assertValue(t, ve.Errors[i].KeywordLocation.String(), w.kw)
+	}
+	assertValue(t, ve.Errors[0].Message, `missing required property "title"`)
+	assertValue(t, ve.Errors[4].Error(), `"/year": must be >= 1450 (/properties/year/minimum)`)
+	if !strings.HasPrefix(err.Error(), "json schema validation failed: ") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestSchemaRecursion(t *testing.T) {
+	s := mustCompileSchema(t, `{"$ref": "#"}`)
+	if err := s.Validate(mustParse(t, `1`)); !errors.Is(err, lept.ErrValidation) {
+		t.Errorf("got error %v want %v", err, lept.ErrValidation)
+	}
+}
+
+func TestInvalidSchema(t *testing.T) {
+	schemas := []string{
+		`1`,
+		`{"type": "int"}`,
+		`{"type": 1}`,
+		`{"enum": 1}`,
+		`{"minimum": "1"}`,
+		`{"multipleOf": 0}`,
+		`{"type": []}`,
+		`{"minLength": -1}`,
+		`{"maxItems": 1.5}`,
+		`{"pattern": "("}`,
+		`{"required": [1]}`,
+		`{"properties": {"a": 1}}`,
+		`{"patternProperties": {"(": {}}}`,
+		`{"allOf": []}`,
+		`{"anyOf": {}}`,
+		`{"items": [{}]}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "#nope"}`,
+		`{"$ref": "https://example.com/other.json"}`,
+		`{"$defs": {"a": "b"}}`,
+		`{"not": null}`,
+	}
+	for _, schema := range schemas {
+		if _, err := lept.CompileSchema(mustParse(t, schema)); !errors.Is(err, lept.ErrInvalidSchema) {
+			t.Errorf("schema %s: got error %v want %v", schema, err, lept.ErrInvalidSchema)
+		}
+	}
+}
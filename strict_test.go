@@ -0,0 +1,98 @@
+package lept_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+type Config struct {
+	Name    string `json:"name,required"`
+	Port    int    `json:"port,omitempty,required"`
+	Debug   bool   `json:"debug"`
+	Backend struct {
+		Host string `json:"host,required"`
+	} `json:"backend"`
+}
+
+func TestStrictDecode(t *testing.T) {
+	tests := []struct {
+		json string
+		opts lept.DecodeOptions
+		want error
+		msg  string
+	}{
+		{`{"name": "a", "port": 1, "backend": {"host": "h"}}`, lept.DecodeOptions{}, nil, ""},
+		{`{"name": "a", "port": 1, "dbug": true, "backend": {"host": "h"}}`, lept.DecodeOptions{}, nil, ""},
+		{`{"name": "a", "port": 1, "dbug": true, "backend": {"host": "h"}}`, lept.DecodeOptions{DisallowUnknownFields: true},
+			lept.ErrUnknownField, `unknown field "dbug"`},
+		{`{"name": "a", "port": 1, "backend": {"host": "h", "hots": "h"}}`, lept.DecodeOptions{DisallowUnknownFields: true},
+			lept.ErrUnknownField, `unknown field "backend.hots"`},
+		{`{"NAME": "a", "Port": 1, "backend": {"HOST": "h"}}`, lept.DecodeOptions{DisallowUnknownFields: true}, nil, ""},
+		{`{"port": 1, "backend": {"host": "h"}}`, lept.DecodeOptions{},
+			lept.ErrMissingField, `missing required field "name"`},
+		{`{"name": "a", "port": 1, "backend": {}}`, lept.DecodeOptions{},
+			lept.ErrMissingField, `missing required field "backend.host"`},
+		{`{"name": "a", "port": 1}`, lept.DecodeOptions{}, nil, ""},
+		{`{"name": "a", "port": 1, "name": "b", "backend": {"host": "h"}}`, lept.DecodeOptions{}, nil, ""},
+		{`{"name": "a", "port": 1, "name": "b", "backend": {"host": "h"}}`, lept.DecodeOptions{DisallowDuplicateKeys: true},
+			lept.ErrDuplicateKey, `duplicate key "name"`},
+		{`{"name": "a", "port": 1, "backend": {"host": "h", "host": "h"}}`, lept.DecodeOptions{DisallowDuplicateKeys: true},
+			lept.ErrDuplicateKey, `duplicate key "backend.host"`},
+	}
+
+	for _, tt := range tests {
+		v, err := lept.Parse(tt.json)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tt.opts.Unmarshal(v, &Config{})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("unmarshal %s: got error %v want %v", tt.json, err, tt.want)
+			continue
+		}
+		if err != nil {
+			assertValue(t, err.Error(), tt.msg)
+		}
+	}
+}
+
+func TestStrictDecodeDuplicates(t *testing.T) {
+	opts := lept.DecodeOptions{DisallowDuplicateKeys: true}
+	for _, json := range []string{`{"a": 1, "a": 2}`, `[{"b": {"c": 1, "c": 1}}]`} {
+		v, err := lept.Parse(json)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var x any
+		if err := opts.Unmarshal(v, &x); !errors.Is(err, lept.ErrDuplicateKey) {
+			t.Errorf("unmarshal %s into any: got error %v want %v", json, err, lept.ErrDuplicateKey)
+		}
+		var m []map[string]map[string]int
+		if err := opts.Unmarshal(v, &m); err == nil {
+			t.Errorf("unmarshal %s into map: expected error", json)
+		}
+	}
+}
+
+func TestDecoderUnmarshal(t *testing.T) {
+	dec := lept.NewDecoder(strings.NewReader(`{"name": "a", "port": 1, "backend": {"host": "h"}}
+{"name": "b", "prot": 2, "backend": {"host": "h"}}`))
+	dec.DisallowUnknownFields()
+	dec.DisallowDuplicateKeys()
+
+	c := Config{}
+	if err := dec.Unmarshal(&c); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, c.Name, "a")
+	if err := dec.Unmarshal(&c); !errors.Is(err, lept.ErrUnknownField) {
+		t.Errorf("got error %v want %v", err, lept.ErrUnknownField)
+	}
+	if err := dec.Unmarshal(&c); err != io.EOF {
+		t.Errorf("got error %v want %v", err, io.EOF)
+	}
+}
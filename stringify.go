@@ -0,0 +1,135 @@
+package lept
+
+import (
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+const hex = "0123456789abcdef"
+
+func Stringify(v *Value) (string, error) {
+	e := encodeState{}
+	if err := e.value(v, 0); err != nil {
+		return "", err
+	}
+	return string(e.buf), nil
+}
+
+func (v *Value) MarshalJSON() ([]byte, error) {
+	e := encodeState{}
+	if err := e.value(v, 0); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+func appendNumber(buf []byte, n any) ([]byte, error) {
+	switch n := n.(type) {
+	case Number:
+		if !isNumber(string(n)) {
+			return nil, errorf("%w: number %q", ErrUnsupportedValue, n)
+		}
+		return append(buf, n...), nil
+	case float64:
+		return appendFloat(buf, n, 64)
+	case float32:
+		return appendFloat(buf, float64(n), 32)
+	case int:
+		return strconv.AppendInt(buf, int64(n), 10), nil
+	case int8:
+		return strconv.AppendInt(buf, int64(n), 10), nil
+	case int16:
+		return strconv.AppendInt(buf, int64(n), 10), nil
+	case int32:
+		return strconv.AppendInt(buf, int64(n), 10), nil
+	case int64:
+		return strconv.AppendInt(buf, n, 10), nil
+	case uint:
+		return strconv.AppendUint(buf, uint64(n), 10), nil
+	case uint8:
+		return strconv.AppendUint(buf, uint64(n), 10), nil
+	case uint16:
+		return strconv.AppendUint(buf, uint64(n), 10), nil
+	case uint32:
+		return strconv.AppendUint(buf, uint64(n), 10), nil
+	case uint64:
+		return strconv.AppendUint(buf, n, 10), nil
+	default:
+		return nil, ErrMismatchType
+	}
+}
+
+// appendFloat uses the shortest representation that round-trips, switching
+// to exponent form in the same ranges as ECMAScript's Number.toString.
+func appendFloat(buf []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, errorf("%w: %v", ErrUnsupportedValue, f)
+	}
+
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 {
+		if bits == 32 {
+			abs = float64(float32(abs))
+		}
+		if abs < 1e-6 || abs >= 1e21 {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf, nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
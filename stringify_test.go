@@ -0,0 +1,103 @@
+package lept_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func TestStringify(t *testing.T) {
+	t.Run("literal", func(t *testing.T) {
+		testRoundtrip(t, "null")
+		testRoundtrip(t, "false")
+		testRoundtrip(t, "true")
+	})
+
+	t.Run("number", func(t *testing.T) {
+		testRoundtrip(t, "0")
+		testRoundtrip(t, "-0")
+		testRoundtrip(t, "1")
+		testRoundtrip(t, "-1")
+		testRoundtrip(t, "1.5")
+		testRoundtrip(t, "-1.5")
+		testRoundtrip(t, "3.25")
+		testRoundtrip(t, "1e+21")
+		testRoundtrip(t, "1.234e-7")
+		testRoundtrip(t, "-1.234e+21")
+		testRoundtrip(t, "0.000001")
+		testRoundtrip(t, "1.0000000000000002")
+		testRoundtrip(t, "5e-324")
+		testRoundtrip(t, "2.225073858507201e-308")
+		testRoundtrip(t, "1.7976931348623157e+308")
+		testRoundtrip(t, "-1.7976931348623157e+308")
+	})
+
+	t.Run("string", func(t *testing.T) {
+		testRoundtrip(t, `""`)
+		testRoundtrip(t, `"Hello"`)
+		testRoundtrip(t, `"Hello\nWorld"`)
+		testRoundtrip(t, `"\" \\ / \b \f \n \r \t"`)
+		testRoundtrip(t, `"Hello\u0000World"`)
+		testRoundtrip(t, `"\u001f"`)
+		testRoundtrip(t, `"日本語 𝄞"`)
+	})
+
+	t.Run("array", func(t *testing.T) {
+		testRoundtrip(t, "[]")
+		testRoundtrip(t, `[null,false,true,123,"abc",[1,2,3]]`)
+	})
+
+	t.Run("object", func(t *testing.T) {
+		testRoundtrip(t, "{}")
+		testRoundtrip(t, `{"n":null,"f":false,"t":true,"i":123,"s":"abc","a":[1,2,3],"o":{"1":1,"2":2,"3":3}}`)
+	})
+}
+
+func testRoundtrip(t *testing.T, json string) {
+	t.Helper()
+	v, err := lept.Parse(json)
+	if err != nil {
+		t.Fatalf("parse %q failed: %v", json, err)
+	}
+	got, err := lept.Stringify(v)
+	if err != nil {
+		t.Fatalf("stringify %q failed: %v", json, err)
+	}
+	assertValue(t, got, json)
+}
+
+func TestStringifyEdited(t *testing.T) {
+	v, err := lept.Parse(`{ "title": "Design Patterns", "author": [ "Erich Gamma" ] }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Get("author").Append(lept.NewString("Richard Helm"))
+	v.Set("year", lept.NewNumber(2009)).Set("tags", lept.NewArray())
+
+	got, err := v.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, string(got), `{"title":"Design Patterns","author":["Erich Gamma","Richard Helm"],"year":2009,"tags":[]}`)
+
+	got, err = lept.NewString("\x01\u2028\xff").MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, string(got), "\"\\u0001\u2028\\ufffd\"")
+}
+
+func TestStringifyUnsupported(t *testing.T) {
+	for _, n := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := lept.Stringify(lept.NewNumber(n)); err == nil {
+			t.Errorf("stringify %v: expected error", n)
+		}
+	}
+
+	_, err := lept.Stringify(&lept.Value{Type: lept.TypeString, U: 1})
+	if !errors.Is(err, lept.ErrMismatchType) {
+		t.Errorf("got error %v want %v", err, lept.ErrMismatchType)
+	}
+}
@@ -0,0 +1,329 @@
+package lept
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrNotValue = errors.New("next token is an object key")
+
+type TokenKind int
+
+const (
+	TokenBeginObject TokenKind = iota
+	TokenEndObject
+	TokenBeginArray
+	TokenEndArray
+	TokenKey
+	TokenString
+	TokenNumber
+	TokenBool
+	TokenNull
+)
+
+var tokenNames = [...]string{
+	TokenBeginObject: "BeginObject",
+	TokenEndObject:   "EndObject",
+	TokenBeginArray:  "BeginArray",
+	TokenEndArray:    "EndArray",
+	TokenKey:         "Key",
+	TokenString:      "String",
+	TokenNumber:      "Number",
+	TokenBool:        "Bool",
+	TokenNull:        "Null",
+}
+
+func (k TokenKind) String() string {
+	if k < 0 || int(k) >= len(tokenNames) {
+		return "Unknown"
+	}
+	return tokenNames[k]
+}
+
+type Token struct {
+	Kind   TokenKind
+	Offset int    // byte offset of the token in the input
+	Value  *Value // decoded scalar, or the member name for Key tokens
+}
+
+type tokenState int
+
+const (
+	stateValue      tokenState = iota // a value is required
+	stateFirstValue                   // after '[', a value or ']'
+	stateFirstKey                     // after '{', a key or '}'
+	stateKey                          // after ',' in an object
+	stateComma                        // after a value inside a container
+	stateEnd                          // the root value is complete
+)
+
+type Tokenizer struct {
+	c     *Context
+	stack []rune // open brackets, innermost last
+	state tokenState
+	err   error
+}
+
+func NewTokenizer(data string) *Tokenizer {
+	return &Tokenizer{c: newContext(data)}
+}
+
+// Depth reports how many containers enclose the current position.
+func (t *Tokenizer) Depth() int {
+	return len(t.stack)
+}
+
+// Next returns the next token, or io.EOF once the root value and any
+// trailing whitespace have been consumed.
+func (t *Tokenizer) Next() (Token, error) {
+	if t.err != nil {
+		return Token{}, t.err
+	}
+	tok, err := t.next()
+	if err != nil {
+		return Token{}, t.fail(err)
+	}
+	return tok, nil
+}
+
+func (t *Tokenizer) next() (Token, error) {
+	c := t.c
+	c.parseWhitespace()
+
+	switch t.state {
+	case stateEnd:
+		if c.isAtEnd() {
+			return Token{}, io.EOF
+		}
+		return Token{}, ErrPluralRoot
+	case stateComma:
+		if err := t.comma(); err != nil {
+			return Token{}, err
+		}
+		if t.state == stateComma {
+			return t.close()
+		}
+		c.parseWhitespace()
+	}
+
+	switch t.state {
+	case stateFirstKey, stateKey:
+		if t.state == stateFirstKey && c.peek() == '}' {
+			return t.close()
+		}
+		return t.key()
+	case stateFirstValue:
+		if c.peek() == ']' {
+			return t.close()
+		}
+	}
+	if c.isAtEnd() && len(t.stack) > 0 && t.stack[len(t.stack)-1] == '[' {
+		return Token{}, ErrMissSquareBracket
+	}
+	return t.value()
+}
+
+// comma consumes the separator after a container element. It leaves the
+// state at stateComma when the container is about to close instead.
+func (t *Tokenizer) comma() error {
+	c := t.c
+	top := t.stack[len(t.stack)-1]
+	switch c.peek() {
+	case ',':
+		c.next()
+		if top == '{' {
+			t.state = stateKey
+		} else {
+			t.state = stateValue
+		}
+		return nil
+	case closing(top):
+		return nil
+	default:
+		// like Parse, which also reports a missing comma at EOF
+		return ErrMissComma
+	}
+}
+
+func (t *Tokenizer) close() (Token, error) {
+	offset := t.c.pos
+	t.c.next()
+	top := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	t.afterValue()
+	if top == '{' {
+		return Token{Kind: TokenEndObject, Offset: offset}, nil
+	}
+	return Token{Kind: TokenEndArray, Offset: offset}, nil
+}
+
+func (t *Tokenizer) key() (Token, error) {
+	c := t.c
+	offset := c.pos
+	if c.peek() != '"' {
+		if c.isAtEnd() {
+			return Token{}, ErrMissCurlyBracket
+		}
+		return Token{}, ErrMissKey
+	}
+	k := &Value{}
+	if err := k.parseString(c); err != nil {
+		return Token{}, err
+	}
+	c.parseWhitespace()
+	if c.peek() != ':' {
+		return Token{}, ErrMissColon
+	}
+	c.next()
+	t.state = stateValue
+	return Token{Kind: TokenKey, Offset: offset, Value: k}, nil
+}
+
+func (t *Tokenizer) value() (Token, error) {
+	c := t.c
+	offset := c.pos
+	switch c.peek() {
+	case '{':
+		c.next()
+		t.stack = append(t.stack, '{')
+		t.state = stateFirstKey
+		return Token{Kind: TokenBeginObject, Offset: offset}, nil
+	case '[':
+		c.next()
+		t.stack = append(t.stack, '[')
+		t.state = stateFirstValue
+		return Token{Kind: TokenBeginArray, Offset: offset}, nil
+	}
+
+	v := &Value{}
+	if err := v.parseValue(c); err != nil {
+		return Token{}, err
+	}
+	t.afterValue()
+
+	tok := Token{Offset: offset, Value: v}
+	switch v.Type {
+	case TypeString:
+		tok.Kind = TokenString
+	case TypeNumber:
+		tok.Kind = TokenNumber
+	case TypeTrue, TypeFalse:
+		tok.Kind = TokenBool
+	default:
+		tok.Kind = TokenNull
+	}
+	return tok, nil
+}
+
+func (t *Tokenizer) afterValue() {
+	if len(t.stack) == 0 {
+		t.state = stateEnd
+	} else {
+		t.state = stateComma
+	}
+}
+
+// ReadValue decodes the next value, including any nested containers, into a
+// Value tree. It is meant for materializing the parts of a stream a caller
+// is interested in, e.g. right after a Key token or inside an array.
+func (t *Tokenizer) ReadValue() (*Value, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	v, err := t.readValue()
+	if err != nil {
+		return nil, t.fail(err)
+	}
+	return v, nil
+}
+
+func (t *Tokenizer) readValue() (*Value, error) {
+	if err := t.prepareValue(); err != nil {
+		return nil, err
+	}
+	v := &Value{}
+	if err := v.parseValue(t.c); err != nil {
+		return nil, err
+	}
+	t.afterValue()
+	return v, nil
+}
+
+// Skip consumes the next value without building it. Calling Skip right
+// after a Key token skips that member's value.
+func (t *Tokenizer) Skip() error {
+	if t.err != nil {
+		return t.err
+	}
+	if err := t.prepareValue(); err != nil {
+		return t.fail(err)
+	}
+
+	depth := len(t.stack)
+	for {
+		if _, err := t.Next(); err != nil {
+			return err
+		}
+		if len(t.stack) == depth {
+			return nil
+		}
+	}
+}
+
+// fail records err as a *SyntaxError unless it only reports that no value
+// can be read at the current position, in which case Next can still make
+// progress.
+func (t *Tokenizer) fail(err error) error {
+	if err == io.EOF || err == ErrNotValue {
+		return err
+	}
+	t.err = t.c.syntaxError(err)
+	return t.err
+}
+
+// prepareValue positions the tokenizer in front of the next value. ReadValue
+// and Skip report io.EOF when the enclosing container or the input has no
+// more values, so callers can loop over array elements until io.EOF and then
+// call Next to consume the closing bracket.
+func (t *Tokenizer) prepareValue() error {
+	c := t.c
+	c.parseWhitespace()
+	switch t.state {
+	case stateEnd:
+		if c.isAtEnd() {
+			return io.EOF
+		}
+		return ErrPluralRoot
+	case stateComma:
+		if err := t.comma(); err != nil {
+			return err
+		}
+		if t.state == stateComma {
+			return io.EOF
+		}
+		c.parseWhitespace()
+	}
+
+	switch t.state {
+	case stateFirstValue:
+		if c.peek() == ']' {
+			return io.EOF
+		}
+	case stateFirstKey:
+		if c.peek() == '}' {
+			return io.EOF
+		}
+		return ErrNotValue
+	case stateKey:
+		return ErrNotValue
+	}
+	t.state = stateValue
+	return nil
+}
+
+func closing(r rune) rune {
+	if r == '{' {
+		return '}'
+	}
+	return ']'
+}
@@ -0,0 +1,173 @@
+package lept_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+func TestTokenizer(t *testing.T) {
+	data := ` {"a": [1, "x", true, null], "b": {}, "c": [] } `
+	want := []struct {
+		kind   lept.TokenKind
+		offset int
+		value  string
+	}{
+		{lept.TokenBeginObject, 1, ""},
+		{lept.TokenKey, 2, `"a"`},
+		{lept.TokenBeginArray, 7, ""},
+		{lept.TokenNumber, 8, "1"},
+		{lept.TokenString, 11, `"x"`},
+		{lept.TokenBool, 16, "true"},
+		{lept.TokenNull, 22, "null"},
+		{lept.TokenEndArray, 26, ""},
+		{lept.TokenKey, 29, `"b"`},
+		{lept.TokenBeginObject, 34, ""},
+		{lept.TokenEndObject, 35, ""},
+		{lept.TokenKey, 38, `"c"`},
+		{lept.TokenBeginArray, 43, ""},
+		{lept.TokenEndArray, 44, ""},
+		{lept.TokenEndObject, 46, ""},
+	}
+
+	tok := lept.NewTokenizer(data)
+	for _, w := range want {
+		got, err := tok.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertValue(t, got.Kind, w.kind)
+		assertValue(t, got.Offset, w.offset)
+		if got.Value != nil {
+			s, _ := lept.Stringify(got.Value)
+			assertValue(t, s, w.value)
+		}
+	}
+	for range 2 {
+		if _, err := tok.Next(); err != io.EOF {
+			t.Fatalf("got error %v want %v", err, io.EOF)
+		}
+	}
+}
+
+func TestTokenizerSkip(t *testing.T) {
+	tok := lept.NewTokenizer(`{"skip": {"x": [1, {"y": 2}]}, "keep": "v", "rest": [3]}`)
+	mustToken(t, tok, lept.TokenBeginObject)
+	mustToken(t, tok, lept.TokenKey)
+	if err := tok.Skip(); err != nil {
+		t.Fatal(err)
+	}
+	k := mustToken(t, tok, lept.TokenKey)
+	assertValue(t, k.Value.STRING(), "keep")
+	assertValue(t, mustToken(t, tok, lept.TokenString).Value.STRING(), "v")
+	mustToken(t, tok, lept.TokenKey)
+	mustToken(t, tok, lept.TokenBeginArray)
+	assertValue(t, tok.Depth(), 2)
+	if err := tok.Skip(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tok.Skip(); err != io.EOF {
+		t.Fatalf("got error %v want %v", err, io.EOF)
+	}
+	mustToken(t, tok, lept.TokenEndArray)
+	mustToken(t, tok, lept.TokenEndObject)
+	assertValue(t, tok.Depth(), 0)
+}
+
+func TestTokenizerReadValue(t *testing.T) {
+	tok := lept.NewTokenizer(`[{"id": 1, "tags": ["a"]}, {"id": 2, "tags": []}, 3]`)
+	mustToken(t, tok, lept.TokenBeginArray)
+
+	ids := []float64{}
+	for {
+		v, err := tok.ReadValue()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Type == lept.TypeObject {
+			ids = append(ids, v.Get("id").NUMBER())
+		}
+	}
+	assertValue(t, len(ids), 2)
+	assertValue(t, ids[1], 2.0)
+	mustToken(t, tok, lept.TokenEndArray)
+
+	tok = lept.NewTokenizer(`{"a": 1}`)
+	mustToken(t, tok, lept.TokenBeginObject)
+	if _, err := tok.ReadValue(); err == nil {
+		t.Error("read value in front of a key: expected error")
+	}
+	mustToken(t, tok, lept.TokenKey)
+	v, err := tok.ReadValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, v.NUMBER(), 1.0)
+	mustToken(t, tok, lept.TokenEndObject)
+}
+
+func TestTokenizerErrors(t *testing.T) {
+	tests := []struct {
+		json string
+		want error
+	}{
+		{`[1 2]`, lept.ErrMissComma},
+		{`{"a" 1}`, lept.ErrMissColon},
+		{`{1: 2}`, lept.ErrMissKey},
+		{`[1, 2`, lept.ErrMissComma},
+		{`{"a": 1`, lept.ErrMissComma},
+		{`[1, `, lept.ErrMissSquareBracket},
+		{`[`, lept.ErrMissSquareBracket},
+		{`{"a": `, lept.ErrExpectValue},
+		{`{"a": 1, `, lept.ErrMissCurlyBracket},
+		{`1 2`, lept.ErrPluralRoot},
+		{`["\x"]`, lept.ErrInvalidStringEscape},
+	}
+
+	for _, tt := range tests {
+		tok := lept.NewTokenizer(tt.json)
+		var err error
+		for err == nil {
+			_, err = tok.Next()
+		}
+		if !errors.Is(err, tt.want) {
+			t.Errorf("tokenize %q: got error %v want %v", tt.json, err, tt.want)
+		}
+		if _, again := tok.Next(); again != err {
+			t.Errorf("tokenize %q: error is not sticky", tt.json)
+		}
+		if _, err := lept.Parse(tt.json); !errors.Is(err, tt.want) {
+			t.Errorf("parse %q: got error %v want %v", tt.json, err, tt.want)
+		}
+	}
+
+	tok := lept.NewTokenizer("[\n  1,\n  2 3\n]")
+	var err error
+	for err == nil {
+		_, err = tok.Next()
+	}
+	var se *lept.SyntaxError
+	if !errors.As(err, &se) {
+		t.Fatalf("got error %T want *lept.SyntaxError", err)
+	}
+	assertValue(t, se.Offset, 11)
+	assertValue(t, se.Line, 3)
+	assertValue(t, se.Column, 5)
+}
+
+func mustToken(t *testing.T, tok *lept.Tokenizer, kind lept.TokenKind) lept.Token {
+	t.Helper()
+	got, err := tok.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Kind != kind {
+		t.Fatalf("got token %v want %v", got.Kind, kind)
+	}
+	return got
+}
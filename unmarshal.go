@@ -0,0 +1,460 @@
+package lept
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+var numberType = reflect.TypeFor[Number]()
+
+var ErrUnknownField = errors.New("unknown field")
+var ErrMissingField = errors.New("missing required field")
+var ErrDuplicateKey = errors.New("duplicate key")
+
+type DecodeOptions struct {
+	DisallowUnknownFields bool // reject object members that match no struct field
+	DisallowDuplicateKeys bool // reject objects that repeat a key
+	UseNumber             bool // decode numbers into interface values as Number instead of float64
+}
+
+func Unmarshal(parsed *Value, v any) error {
+	return DecodeOptions{}.Unmarshal(parsed, v)
+}
+
+func (o DecodeOptions) Unmarshal(parsed *Value, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errorf("Attempt to unmarshal into a non-pointer")
+	}
+
+	d := &decodeState{opts: o}
+	return d.unmarshalValue(parsed, rv.Elem())
+}
+
+type decodeState struct {
+	opts DecodeOptions
+	path []string // object keys and "[i]" array indices leading to the current value
+}
+
+// fieldError reports err for the member key of the current object.
+func (d *decodeState) fieldError(err error, key string) error {
+	d.path = append(d.path, key)
+	field := d.field()
+	d.path = d.path[:len(d.path)-1]
+	return errorf("%w %q", err, field)
+}
+
+func (d *decodeState) checkDuplicates(obj Object) error {
+	if !d.opts.DisallowDuplicateKeys || len(obj) < 2 {
+		return nil
+	}
+	seen := make(map[string]bool, len(obj))
+	for _, m := range obj {
+		if seen[m.K] {
+			return d.fieldError(ErrDuplicateKey, m.K)
+		}
+		seen[m.K] = true
+	}
+	return nil
+}
+
+func (d *decodeState) field() string {
+	sb := strings.Builder{}
+	for i, p := range d.path {
+		if i > 0 && !strings.HasPrefix(p, "[") {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(p)
+	}
+	return sb.String()
+}
+
+func (d *decodeState) mismatch(parsed *Value, v reflect.Value) error {
+	return &UnmarshalTypeError{Value: parsed.Type, Type: v.Type(), Field: d.field()}
+}
+
+func (d *decodeState) unmarshalElem(parsed *Value, v reflect.Value, key string) error {
+	d.path = append(d.path, key)
+	err := d.unmarshalValue(parsed, v)
+	d.path = d.path[:len(d.path)-1]
+	return err
+}
+
+type Unmarshaler interface {
+	UnmarshalLept(*Value) error
+}
+
+// indirect walks down v allocating nil pointers until it reaches a
+// non-pointer or a value implementing one of the unmarshaler interfaces.
+// When decoding null it stops at the first settable pointer so that the
+// caller can clear it.
+func indirect(v reflect.Value, null bool) (Unmarshaler, json.Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	// a named value is addressable when it is a struct field or an element,
+	// so its pointer methods can be used as well
+	v0 := v
+	haveAddr := false
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		haveAddr = true
+		v = v.Addr()
+	}
+
+	for {
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			e := v.Elem()
+			if e.Kind() == reflect.Pointer && !e.IsNil() && (!null || e.Elem().Kind() == reflect.Pointer) {
+				haveAddr = false
+				v = e
+				continue
+			}
+		}
+
+		if v.Kind() != reflect.Pointer {
+			break
+		}
+		if null && v.CanSet() {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().NumMethod() > 0 && v.CanInterface() {
+			if u, ok := v.Interface().(Unmarshaler); ok {
+				return u, nil, nil, reflect.Value{}
+			}
+			if u, ok := v.Interface().(json.Unmarshaler); ok {
+				return nil, u, nil, reflect.Value{}
+			}
+			if !null {
+				if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+					return nil, nil, u, reflect.Value{}
+				}
+			}
+		}
+
+		if haveAddr {
+			v = v0
+			haveAddr = false
+		} else {
+			v = v.Elem()
+		}
+	}
+	return nil, nil, nil, v
+}
+
+func (d *decodeState) unmarshalValue(parsed *Value, v reflect.Value) (err error) {
+	u, ju, tu, pv := indirect(v, parsed.Type == TypeNull)
+	switch {
+	case u != nil:
+		return u.UnmarshalLept(parsed)
+	case ju != nil:
+		data, err := parsed.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		return ju.UnmarshalJSON(data)
+	case tu != nil:
+		if parsed.Type != TypeString {
+			return d.mismatch(parsed, v)
+		}
+		return tu.UnmarshalText([]byte(parsed.STRING()))
+	}
+
+	v = pv
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		x, err := d.valueInterface(parsed)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	}
+
+	switch parsed.Type {
+	case TypeTrue, TypeFalse:
+		switch v.Kind() {
+		case reflect.Bool:
+			v.SetBool(parsed.BOOL())
+		default:
+			err = d.mismatch(parsed, v)
+		}
+	case TypeNumber:
+		err = d.unmarshalNumber(parsed, v)
+	case TypeString:
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString(parsed.STRING())
+		default:
+			err = d.mismatch(parsed, v)
+		}
+	case TypeArray:
+		switch v.Kind() {
+		case reflect.Slice:
+			l := reflect.MakeSlice(v.Type(), len(parsed.ARRAY()), len(parsed.ARRAY()))
+			for i, e := range parsed.ARRAY() {
+				if err = d.unmarshalElem(e, l.Index(i), "["+strconv.Itoa(i)+"]"); err != nil {
+					return
+				}
+			}
+			v.Set(l)
+		case reflect.Array:
+			if v.Len() != len(parsed.ARRAY()) {
+				err = fmt.Errorf("array length mismatch: %d vs %d", v.Len(), len(parsed.ARRAY()))
+				return
+			}
+			for i, e := range parsed.ARRAY() {
+				elem := v.Index(i)
+				if err = d.unmarshalElem(e, elem, "["+strconv.Itoa(i)+"]"); err != nil {
+					return
+				}
+			}
+		default:
+			err = d.mismatch(parsed, v)
+		}
+	case TypeObject:
+		if err = d.checkDuplicates(parsed.OBJECT()); err != nil {
+			return
+		}
+		switch v.Kind() {
+		case reflect.Map:
+			err = d.unmarshalMap(parsed, v)
+		case reflect.Struct:
+			err = d.unmarshalStruct(parsed, v)
+		default:
+			err = d.mismatch(parsed, v)
+		}
+	case TypeNull:
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			v.SetZero()
+		}
+	default:
+		err = errUnsupportedType(parsed)
+	}
+	return
+}
+
+func (d *decodeState) unmarshalNumber(parsed *Value, v reflect.Value) error {
+	n, err := parsed.number()
+	if err != nil {
+		return err
+	}
+	if v.Type() == numberType {
+		v.SetString(string(n))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		i, err := n.Int64()
+		if err == nil && v.OverflowInt(i) {
+			err = ErrOutOfRange
+		}
+		if err != nil {
+			return d.numberError(parsed, v, err)
+		}
+		v.SetInt(i)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		u, err := n.Uint64()
+		if err == nil && v.OverflowUint(u) {
+			err = ErrOutOfRange
+		}
+		if err != nil {
+			return d.numberError(parsed, v, err)
+		}
+		v.SetUint(u)
+	case reflect.Float64, reflect.Float32:
+		f, err := n.Float64()
+		if err == nil && v.OverflowFloat(f) {
+			err = ErrOutOfRange
+		}
+		if err != nil {
+			return d.numberError(parsed, v, err)
+		}
+		v.SetFloat(f)
+	default:
+		return d.mismatch(parsed, v)
+	}
+	return nil
+}
+
+func (d *decodeState) numberError(parsed *Value, v reflect.Value, err error) error {
+	return &UnmarshalTypeError{Value: parsed.Type, Type: v.Type(), Field: d.field(), Err: err}
+}
+
+func (d *decodeState) unmarshalStruct(parsed *Value, v reflect.Value) error {
+	fields := cachedFields(v.Type())
+	seen := make([]bool, len(fields))
+	for _, m := range parsed.OBJECT() {
+		i := lookupField(fields, m.K)
+		if i < 0 {
+			if d.opts.DisallowUnknownFields {
+				return d.fieldError(ErrUnknownField, m.K)
+			}
+			continue
+		}
+		seen[i] = true
+
+		f := &fields[i]
+		fv, ok := fieldByIndex(v, f.index, true)
+		if !ok {
+			continue
+		}
+
+		var err error
+		if f.asString {
+			d.path = append(d.path, m.K)
+			err = d.unmarshalQuoted(m.V, fv)
+			d.path = d.path[:len(d.path)-1]
+		} else {
+			err = d.unmarshalElem(m.V, fv, m.K)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, f := range fields {
+		if f.required && !seen[i] {
+			return d.fieldError(ErrMissingField, f.name)
+		}
+	}
+	return nil
+}
+
+// unmarshalQuoted decodes a field tagged with ",string", whose scalar value
+// is wrapped in a JSON string.
+func (d *decodeState) unmarshalQuoted(parsed *Value, v reflect.Value) error {
+	switch parsed.Type {
+	case TypeNull:
+		return nil
+	case TypeString:
+	default:
+		return d.mismatch(parsed, v)
+	}
+
+	inner, err := Parse(parsed.STRING())
+	if err != nil || inner.Type == TypeArray || inner.Type == TypeObject {
+		return errorf("invalid use of ,string struct tag, trying to unmarshal %q into %v", parsed.STRING(), v.Type())
+	}
+	return d.unmarshalValue(inner, v)
+}
+
+func (d *decodeState) unmarshalMap(parsed *Value, v reflect.Value) error {
+	t := v.Type()
+	kt := t.Key()
+	switch {
+	case reflect.PointerTo(kt).Implements(textUnmarshalerType):
+	case kt.Kind() == reflect.String:
+	case isInteger(kt.Kind()):
+	default:
+		return d.mismatch(parsed, v)
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(t))
+	}
+	for _, m := range parsed.OBJECT() {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := d.unmarshalElem(m.V, elem, m.K); err != nil {
+			return err
+		}
+
+		key, err := d.mapKey(m.K, kt)
+		if err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+func (d *decodeState) mapKey(k string, kt reflect.Type) (reflect.Value, error) {
+	if reflect.PointerTo(kt).Implements(textUnmarshalerType) {
+		key := reflect.New(kt)
+		if err := key.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+			return reflect.Value{}, err
+		}
+		return key.Elem(), nil
+	}
+
+	key := reflect.New(kt).Elem()
+	switch kt.Kind() {
+	case reflect.String:
+		key.SetString(k)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		n, err := strconv.ParseInt(k, 10, 64)
+		if err != nil || key.OverflowInt(n) {
+			return reflect.Value{}, &UnmarshalTypeError{Value: TypeString, Type: kt, Field: d.field()}
+		}
+		key.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(k, 10, 64)
+		if err != nil || key.OverflowUint(n) {
+			return reflect.Value{}, &UnmarshalTypeError{Value: TypeString, Type: kt, Field: d.field()}
+		}
+		key.SetUint(n)
+	}
+	return key, nil
+}
+
+// valueInterface converts parsed into the Go value an empty interface holds
+// after decoding: bool, float64, string, []any, map[string]any or nil.
+func (d *decodeState) valueInterface(parsed *Value) (any, error) {
+	switch parsed.Type {
+	case TypeTrue, TypeFalse:
+		return parsed.BOOL(), nil
+	case TypeNumber:
+		if d.opts.UseNumber {
+			return parsed.number()
+		}
+		return parsed.NUMBER(), nil
+	case TypeString:
+		return parsed.STRING(), nil
+	case TypeArray:
+		arr := make([]any, len(parsed.ARRAY()))
+		for i, e := range parsed.ARRAY() {
+			x, err := d.valueInterface(e)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = x
+		}
+		return arr, nil
+	case TypeObject:
+		if err := d.checkDuplicates(parsed.OBJECT()); err != nil {
+			return nil, err
+		}
+		obj := make(map[string]any, len(parsed.OBJECT()))
+		for _, m := range parsed.OBJECT() {
+			x, err := d.valueInterface(m.V)
+			if err != nil {
+				return nil, err
+			}
+			obj[m.K] = x
+		}
+		return obj, nil
+	case TypeNull:
+		return nil, nil
+	default:
+		return nil, errUnsupportedType(parsed)
+	}
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package lept_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wasuppu/lept"
+)
+
+type upperKey string
+
+func (k *upperKey) UnmarshalText(text []byte) error {
+	*k = upperKey(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	testUnmarshal(t, `{"a": 1, "b": 2}`, map[string]int{"a": 1, "b": 2})
+	testUnmarshal(t, `{"1": "a", "-2": "b"}`, map[int]string{1: "a", -2: "b"})
+	testUnmarshal(t, `{"1": "a", "2": "b"}`, map[uint8]string{1: "a", 2: "b"})
+	testUnmarshal(t, `{"a": 1, "b": 2}`, map[upperKey]float64{"A": 1, "B": 2})
+	testUnmarshal(t, `{"a": [1, 2], "b": null}`, map[string][]int{"a": {1, 2}, "b": nil})
+	testUnmarshal(t, `{"a": {"x": true}}`, map[string]map[string]bool{"a": {"x": true}})
+	testUnmarshal(t, `{"a": 1, "a": 2}`, map[string]int{"a": 2})
+
+	m := map[string]int{"keep": 1}
+	v, _ := lept.Parse(`{"add": 2}`)
+	if err := lept.Unmarshal(v, &m); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, map[string]int{"keep": 1, "add": 2}) {
+		t.Errorf("got %v", m)
+	}
+
+	for _, json := range []string{`{"256": "a"}`, `{"x": "a"}`} {
+		v, _ := lept.Parse(json)
+		if err := lept.Unmarshal(v, new(map[uint8]string)); err == nil {
+			t.Errorf("unmarshal %s: expected error", json)
+		}
+	}
+	v, _ = lept.Parse(`{"a": 1}`)
+	if err := lept.Unmarshal(v, new(map[float64]int)); err == nil {
+		t.Error("unmarshal into map[float64]int: expected error")
+	}
+}
+
+func TestUnmarshalPointer(t *testing.T) {
+	type Publisher struct {
+		Company string `json:"Company"`
+	}
+	type Book struct {
+		Title     *string    `json:"title"`
+		Year      **int      `json:"year"`
+		Publisher *Publisher `json:"publisher"`
+		Website   *string    `json:"website"`
+		Tags      *[]string  `json:"tags"`
+	}
+
+	v, err := lept.Parse(`{"title": "Design Patterns", "year": 2009, "publisher": {"Company": "Pearson Education"}, "website": null, "tags": ["oop"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	website := "example.com"
+	book := Book{Website: &website}
+	if err := lept.Unmarshal(v, &book); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, *book.Title, "Design Patterns")
+	assertValue(t, **book.Year, 2009)
+	assertValue(t, book.Publisher.Company, "Pearson Education")
+	assertValue(t, book.Website, nil)
+	assertValue(t, (*book.Tags)[0], "oop")
+
+	var p *Book
+	if err := lept.Unmarshal(v, &p); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, p.Publisher.Company, "Pearson Education")
+
+	null, _ := lept.Parse(`null`)
+	if err := lept.Unmarshal(null, &p); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, p, nil)
+
+	if err := lept.Unmarshal(v, book); err == nil {
+		t.Error("unmarshal into non-pointer: expected error")
+	}
+	if err := lept.Unmarshal(v, (*Book)(nil)); err == nil {
+		t.Error("unmarshal into nil pointer: expected error")
+	}
+}
+
+func TestUnmarshalInterface(t *testing.T) {
+	testUnmarshal[any](t, `null`, nil)
+	testUnmarshal[any](t, `true`, true)
+	testUnmarshal[any](t, `1.5`, 1.5)
+	testUnmarshal[any](t, `"s"`, "s")
+	testUnmarshal[any](t, `[1, "a", null, [true]]`, []any{1.0, "a", nil, []any{true}})
+	testUnmarshal[any](t, `{"a": {"b": [1]}, "c": null}`, map[string]any{"a": map[string]any{"b": []any{1.0}}, "c": nil})
+	testUnmarshal(t, `[{"a": 1}, 2]`, []any{map[string]any{"a": 1.0}, 2.0})
+	testUnmarshal(t, `{"a": [1], "b": {}}`, map[string]any{"a": []any{1.0}, "b": map[string]any{}})
+
+	n := 0
+	var x any = &n
+	v, _ := lept.Parse(`42`)
+	if err := lept.Unmarshal(v, &x); err != nil {
+		t.Fatal(err)
+	}
+	assertValue(t, n, 42)
+}
+
+func testUnmarshal[T any](t *testing.T, json string, want T) {
+	t.Helper()
+	v, err := lept.Parse(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got T
+	if err := lept.Unmarshal(v, &got); err != nil {
+		t.Fatalf("unmarshal %s failed: %v", json, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unmarshal %s: got %#v want %#v", json, got, want)
+	}
+}